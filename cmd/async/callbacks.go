@@ -0,0 +1,20 @@
+package async
+
+import (
+	"github.com/spf13/cobra"
+	"go-gladia.io-client/internal/config"
+)
+
+var serveCallbacksCmd = &cobra.Command{
+	Use:   "serve-callbacks",
+	Short: "Receive transcription results via Gladia callbacks",
+	Args:  cobra.NoArgs,
+}
+
+func setServeCallbacksFlags(cfg *config.Config) {
+	serveCallbacksCmd.Flags().StringVarP(&cfg.Listen, "listen", "l", ":8090", "address to listen for callbacks on")
+	serveCallbacksCmd.Flags().StringVar(&cfg.CallbackSecret, "secret", cfg.CallbackSecret, "shared secret expected in the callback url")
+	serveCallbacksCmd.Flags().StringVarP(&cfg.OutputDir, "output-dir", "d", ".", "directory for the received results")
+	serveCallbacksCmd.Flags().StringSliceVarP(&cfg.OutputFormats, "format", "f", []string{"txt"}, "formats to write for every received result")
+	serveCallbacksCmd.Flags().StringSliceVar(&cfg.Redact, "redact", nil, "mask personal data in the result (person, phone, email, card, all)")
+}
@@ -0,0 +1,81 @@
+package async
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go-gladia.io-client/internal/config"
+	"go-gladia.io-client/pkg/output"
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert <result.json|task_id|dir>...",
+	Short: "Convert a saved transcription result to another format offline",
+	Long: `Convert a saved transcription result to another format offline.
+
+Several results (files, task ids or directories with json results) can be
+streamed into one csv, tsv or jsonl file for analysis in pandas or BigQuery.`,
+	Args: cobra.MinimumNArgs(1),
+}
+
+func setConvertFlags(cfg *config.Config) {
+	convertCmd.Flags().StringVarP(&cfg.ConvertTo, "to", "t", "txt", "target format ("+strings.Join(output.Names(), ", ")+")")
+	convertCmd.Flags().StringVarP(&cfg.ConvertOutput, "output", "o", "", "output file, stdout by default")
+	convertCmd.Flags().IntVar(&cfg.MaxLineLength, "max-line-length", 42, "maximum subtitle line length in characters")
+	convertCmd.Flags().IntVar(&cfg.MaxLines, "max-lines", 2, "maximum number of lines per subtitle")
+	convertCmd.Flags().BoolVar(&cfg.SpeakerLabels, "speaker-labels", false, "label subtitles with speakers")
+	convertCmd.Flags().BoolVar(&cfg.WordTimings, "karaoke", false, "word-timed subtitles (vtt inline timestamps, ass \\k tags)")
+	convertCmd.Flags().Float64Var(&cfg.LowConfidence, "low-confidence", 0, "highlight words with confidence below this threshold (0-1)")
+	convertCmd.Flags().BoolVar(&cfg.LineNumbers, "line-numbers", false, "number lines in docx")
+	convertCmd.Flags().StringVar(&cfg.AudioPath, "audio", "", "local audio file or url for the player in md and html reports")
+	convertCmd.Flags().StringVar(&cfg.Rows, "rows", output.RowsUtterance, "rows of csv, tsv and jsonl: utterance or word")
+	convertCmd.Flags().StringSliceVar(&cfg.Translations, "translations", nil, "write translations next to the output file as result.fr.srt (languages or all)")
+	convertCmd.Flags().StringVar(&cfg.Bilingual, "bilingual", "", "show this translation under the original lines in subtitles")
+	convertCmd.Flags().StringVar(&cfg.FrameRate, "frame-rate", "", "frame rate for ttml and stl timecodes (23.976, 24, 25, 29.97, 29.97ndf, 30)")
+	convertCmd.Flags().StringVar(&cfg.StyleSheet, "style-sheet", "", "yaml style sheet for ass/ssa subtitles")
+	convertCmd.Flags().StringVar(&cfg.SpeakerNames, "speaker-names", "", "speaker names as 0=Alice,1=Bob or a yaml file")
+	convertCmd.Flags().StringVar(&cfg.ChannelNames, "channel-names", "", "label speakers by audio channel as 0=Agent,1=Customer or a yaml file")
+}
+
+// Записать несколько результатов в один табличный файл, результаты читаются по одному
+func convertBatch(cfg *config.Config, refs []string, opts output.Options) error {
+	if !output.IsTable(cfg.ConvertTo) {
+		return fmt.Errorf("format %q does not support several results in one file, use csv, tsv or jsonl", cfg.ConvertTo)
+	}
+
+	var w io.Writer = os.Stdout
+	if cfg.ConvertOutput != "" && cfg.ConvertOutput != "-" {
+		file, err := os.Create(cfg.ConvertOutput)
+		if err != nil {
+			return fmt.Errorf("%s: failed create output file: %w", cfg.ConvertOutput, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	tw, err := output.NewTableWriter(w, cfg.ConvertTo, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		resp, err := loadResult(cfg, ref)
+		if err != nil {
+			return err
+		}
+		if err := tw.Write(resp); err != nil {
+			return fmt.Errorf("%s: %w", ref, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if w != os.Stdout {
+		fmt.Fprintf(os.Stderr, "%d results saved to: %s\n", len(refs), cfg.ConvertOutput)
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package async
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"go-gladia.io-client/internal/config"
+	"go-gladia.io-client/internal/eval"
+	"go-gladia.io-client/pkg/output"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <taskA|fileA> <taskB|fileB>",
+	Short: "Compare two transcription results word by word",
+	Long: `Compare two transcription results word by word.
+
+Words are aligned after normalisation. Deleted words (only in A) are shown
+as [-word-], inserted words (only in B) as {+word+} and words that moved to
+another speaker as [~word~], in colour when writing to a terminal. Timing
+drift and speaker reassignment statistics are printed after the diff.`,
+	Args: cobra.ExactArgs(2),
+}
+
+const (
+	colorReset    = "\x1b[0m"
+	colorDeleted  = "\x1b[9;31m"
+	colorInserted = "\x1b[32m"
+	colorMoved    = "\x1b[33m"
+)
+
+func setDiffFlags(cfg *config.Config) {
+	diffCmd.Flags().StringVar(&cfg.DiffView, "view", "inline", "diff view: inline, side-by-side or none (statistics only)")
+	diffCmd.Flags().BoolVar(&cfg.NoColor, "no-color", false, "disable colours, on by default only for terminals")
+	diffCmd.Flags().IntVar(&cfg.Width, "width", 0, "side-by-side width, $COLUMNS or 120 by default")
+	diffCmd.Flags().BoolVar(&cfg.KeepCase, "keep-case", false, "do not lowercase before comparing")
+	diffCmd.Flags().BoolVar(&cfg.KeepPunctuation, "keep-punctuation", false, "do not strip punctuation before comparing")
+	diffCmd.Flags().BoolVar(&cfg.SpellNumbers, "spell-numbers", false, "spell out numbers as english words (42 -> forty two)")
+}
+
+func runDiff(cfg *config.Config, w io.Writer, args []string) error {
+	a, err := loadResult(cfg, args[0])
+	if err != nil {
+		return err
+	}
+	b, err := loadResult(cfg, args[1])
+	if err != nil {
+		return err
+	}
+	if a.Result == nil || b.Result == nil {
+		return fmt.Errorf("both tasks must be completed")
+	}
+
+	n := eval.Normalization{KeepCase: cfg.KeepCase, KeepPunctuation: cfg.KeepPunctuation, SpellNumbers: cfg.SpellNumbers}
+	c := eval.Compare(a.Result, b.Result, n)
+
+	d := differ{c: c, color: useColor(cfg.NoColor)}
+	switch cfg.DiffView {
+	case "inline":
+		d.inline(w)
+	case "side-by-side":
+		width := cfg.Width
+		if width <= 0 {
+			width, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+		}
+		if width <= 0 {
+			width = 120
+		}
+		d.sideBySide(w, width)
+	case "none":
+	default:
+		return fmt.Errorf("unknown view %q, available: inline, side-by-side, none", cfg.DiffView)
+	}
+
+	printDiffStats(w, c, args[0], args[1])
+	return nil
+}
+
+// Цвет только для терминала и без NO_COLOR (https://no-color.org)
+func useColor(disabled bool) bool {
+	if disabled || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+type differ struct {
+	c     eval.Comparison
+	color bool
+}
+
+// Участок выравнивания, относящийся к одному высказыванию A
+type diffChunk struct {
+	ops []eval.Op
+}
+
+func (d differ) chunks() []diffChunk {
+	var chunks []diffChunk
+	current := -1
+	for _, op := range d.c.Ops {
+		utterance := current
+		if op.Ref >= 0 {
+			utterance = d.c.A[op.Ref].Utterance
+		}
+		if len(chunks) == 0 || utterance != current {
+			chunks = append(chunks, diffChunk{})
+			current = utterance
+		}
+		chunks[len(chunks)-1].ops = append(chunks[len(chunks)-1].ops, op)
+	}
+	return chunks
+}
+
+// Слово с разметкой изменения; видимая длина нужна для выравнивания колонок
+type diffToken struct {
+	text    string
+	visible int
+}
+
+func (d differ) mark(word, open, close, color string) diffToken {
+	if d.color {
+		return diffToken{text: color + word + colorReset, visible: utf8.RuneCountInString(word)}
+	}
+	text := open + word + close
+	return diffToken{text: text, visible: utf8.RuneCountInString(text)}
+}
+
+func plainToken(word string) diffToken {
+	return diffToken{text: word, visible: utf8.RuneCountInString(word)}
+}
+
+// Токены левой (A) и правой (B) стороны, для inline все попадает в left
+func (d differ) tokens(ops []eval.Op, split bool) (left, right []diffToken) {
+	for _, op := range ops {
+		switch op.Kind {
+		case eval.OpEqual:
+			wa, wb := d.c.A[op.Ref], d.c.B[op.Hyp]
+			tokA, tokB := plainToken(wa.Text), plainToken(wb.Text)
+			if d.c.Speakers.IsReassigned(wa.Speaker, wb.Speaker) {
+				tokA, tokB = d.mark(wa.Text, "[~", "~]", colorMoved), d.mark(wb.Text, "[~", "~]", colorMoved)
+			}
+			left = append(left, tokA)
+			right = append(right, tokB)
+		case eval.OpSubstitute:
+			left = append(left, d.mark(d.c.A[op.Ref].Text, "[-", "-]", colorDeleted))
+			if split {
+				right = append(right, d.mark(d.c.B[op.Hyp].Text, "{+", "+}", colorInserted))
+			} else {
+				left = append(left, d.mark(d.c.B[op.Hyp].Text, "{+", "+}", colorInserted))
+			}
+		case eval.OpDelete:
+			left = append(left, d.mark(d.c.A[op.Ref].Text, "[-", "-]", colorDeleted))
+		case eval.OpInsert:
+			if split {
+				right = append(right, d.mark(d.c.B[op.Hyp].Text, "{+", "+}", colorInserted))
+			} else {
+				left = append(left, d.mark(d.c.B[op.Hyp].Text, "{+", "+}", colorInserted))
+			}
+		}
+	}
+	return left, right
+}
+
+// Заголовок участка: время и спикер первого слова стороны
+func (d differ) header(words []eval.TimedWord, ops []eval.Op, side func(eval.Op) int) string {
+	for _, op := range ops {
+		if idx := side(op); idx >= 0 {
+			w := words[idx]
+			label := "[" + output.FormatClock(w.Start) + "]"
+			if name := output.SpeakerName(w.Speaker, output.Options{}); name != "" {
+				label += " " + name + ":"
+			}
+			return label
+		}
+	}
+	return ""
+}
+
+func refIndex(op eval.Op) int { return op.Ref }
+func hypIndex(op eval.Op) int { return op.Hyp }
+
+func (d differ) inline(w io.Writer) {
+	for _, chunk := range d.chunks() {
+		tokens, _ := d.tokens(chunk.ops, false)
+		header := d.header(d.c.A, chunk.ops, refIndex)
+		if header == "" {
+			header = d.header(d.c.B, chunk.ops, hypIndex)
+		}
+
+		texts := make([]string, len(tokens))
+		for i, t := range tokens {
+			texts[i] = t.text
+		}
+		fmt.Fprintf(w, "%s %s\n", header, strings.Join(texts, " "))
+	}
+	fmt.Fprintln(w)
+}
+
+func (d differ) sideBySide(w io.Writer, width int) {
+	col := max((width-3)/2, 20)
+	fmt.Fprintf(w, "%s | %s\n", padVisible("A", 1, col), "B")
+	fmt.Fprintf(w, "%s-+-%s\n", strings.Repeat("-", col), strings.Repeat("-", col))
+
+	for _, chunk := range d.chunks() {
+		left, right := d.tokens(chunk.ops, true)
+		if header := d.header(d.c.A, chunk.ops, refIndex); header != "" {
+			left = append([]diffToken{plainToken(header)}, left...)
+		}
+		if header := d.header(d.c.B, chunk.ops, hypIndex); header != "" {
+			right = append([]diffToken{plainToken(header)}, right...)
+		}
+
+		leftLines, rightLines := wrapTokens(left, col), wrapTokens(right, col)
+		for i := 0; i < max(len(leftLines), len(rightLines)); i++ {
+			var l, r diffLine
+			if i < len(leftLines) {
+				l = leftLines[i]
+			}
+			if i < len(rightLines) {
+				r = rightLines[i]
+			}
+			fmt.Fprintf(w, "%s | %s\n", padVisible(l.text, l.visible, col), r.text)
+		}
+		fmt.Fprintf(w, "%s | \n", strings.Repeat(" ", col))
+	}
+}
+
+type diffLine struct {
+	text    string
+	visible int
+}
+
+// Перенос по словам с учетом видимой длины, слово длиннее колонки занимает строку целиком
+func wrapTokens(tokens []diffToken, width int) []diffLine {
+	var lines []diffLine
+	var cur diffLine
+	for _, t := range tokens {
+		if cur.visible > 0 && cur.visible+1+t.visible > width {
+			lines = append(lines, cur)
+			cur = diffLine{}
+		}
+		if cur.visible > 0 {
+			cur.text += " "
+			cur.visible++
+		}
+		cur.text += t.text
+		cur.visible += t.visible
+	}
+	if cur.visible > 0 {
+		lines = append(lines, cur)
+	}
+	return lines
+}
+
+func padVisible(text string, visible, width int) string {
+	if visible >= width {
+		return text
+	}
+	return text + strings.Repeat(" ", width-visible)
+}
+
+func printDiffStats(w io.Writer, c eval.Comparison, nameA, nameB string) {
+	counts := c.Counts
+	fmt.Fprintf(w, "A: %s (%d words)\nB: %s (%d words)\n", nameA, len(c.A), nameB, len(c.B))
+	fmt.Fprintf(w, "Words: %d equal, %d substituted, %d inserted, %d deleted, %.2f%% changed\n",
+		counts.Hits, counts.Substitutions, counts.Insertions, counts.Deletions, counts.Rate()*100)
+
+	if dr := c.Drift; dr.Words > 0 {
+		fmt.Fprintf(w, "Timing drift over %d matched words: mean %+.3fs (end %+.3fs), mean abs %.3fs, median abs %.3fs, max %.3fs at %s\n",
+			dr.Words, dr.Mean, dr.EndMean, dr.MeanAbs, dr.Median, dr.Max, output.FormatClock(dr.MaxAt))
+	}
+	if dr := c.Drift; dr.DurationA > 0 && dr.DurationB > 0 && dr.DurationA != dr.DurationB {
+		fmt.Fprintf(w, "Audio duration: %.3fs vs %.3fs\n", dr.DurationA, dr.DurationB)
+	}
+
+	sp := c.Speakers
+	if sp.Compared == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Speakers: %d of %d matched words reassigned (%.2f%%)\n",
+		sp.Reassigned, sp.Compared, float64(sp.Reassigned)/float64(sp.Compared)*100)
+
+	from := make([]string, 0, len(sp.Mapping))
+	for k := range sp.Mapping {
+		from = append(from, k)
+	}
+	sort.Strings(from)
+	for _, k := range from {
+		fmt.Fprintf(w, "  A %s = B %s\n", diffSpeaker(k), diffSpeaker(sp.Mapping[k]))
+	}
+	for _, ch := range sp.Changes {
+		if sp.Mapping[ch.From] != ch.To {
+			fmt.Fprintf(w, "  moved A %s -> B %s: %d words\n", diffSpeaker(ch.From), diffSpeaker(ch.To), ch.Words)
+		}
+	}
+}
+
+func diffSpeaker(key string) string {
+	n, err := strconv.Atoi(key)
+	if err != nil {
+		return key
+	}
+	return output.SpeakerName(&n, output.Options{})
+}
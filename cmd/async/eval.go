@@ -0,0 +1,156 @@
+package async
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+	"go-gladia.io-client/internal/config"
+	"go-gladia.io-client/internal/eval"
+)
+
+var evalCmd = &cobra.Command{
+	Use:   "eval <result.json|task_id|dir>...",
+	Short: "Compare transcription results with reference transcripts (WER, CER, DER)",
+	Long: `Compare transcription results with reference transcripts.
+
+Reports word and character error rates with substitution, insertion and
+deletion counts. When reference speaker turns are known (speaker-labelled
+srt/vtt or rttm) the diarization error rate is reported too.
+
+With a reference directory each result is matched by name: result.json or
+the original audio file name against name.srt, name.vtt or name.txt, and
+name.rttm for speaker turns.`,
+	Args: cobra.MinimumNArgs(1),
+}
+
+// Расширения эталонов в порядке приоритета
+var referenceExtensions = []string{".srt", ".vtt", ".txt"}
+
+func setEvalFlags(cfg *config.Config) {
+	evalCmd.Flags().StringVarP(&cfg.Reference, "reference", "r", "", "reference transcript (.txt, .srt, .vtt) or a directory of references")
+	evalCmd.Flags().StringVar(&cfg.ReferenceTurns, "speakers", "", "reference speaker turns (.rttm) or a directory, the reference directory by default")
+	evalCmd.Flags().StringVarP(&cfg.EvalFormat, "format", "f", "json", "report format: json or csv")
+	evalCmd.Flags().StringVarP(&cfg.EvalOutput, "output", "o", "", "report file, stdout by default")
+	evalCmd.Flags().BoolVar(&cfg.KeepCase, "keep-case", false, "do not lowercase before comparing")
+	evalCmd.Flags().BoolVar(&cfg.KeepPunctuation, "keep-punctuation", false, "do not strip punctuation before comparing")
+	evalCmd.Flags().BoolVar(&cfg.SpellNumbers, "spell-numbers", false, "spell out numbers as english words (42 -> forty two)")
+	evalCmd.MarkFlagRequired("reference")
+}
+
+func runEval(cfg *config.Config, args []string) error {
+	var write func(io.Writer, []eval.Report) error
+	switch strings.ToLower(cfg.EvalFormat) {
+	case "json":
+		write = eval.WriteJSON
+	case "csv":
+		write = eval.WriteCSV
+	default:
+		return fmt.Errorf("unknown report format %q, available: json, csv", cfg.EvalFormat)
+	}
+
+	refs, err := expandResultRefs(args)
+	if err != nil {
+		return err
+	}
+
+	refIsDir := isDir(cfg.Reference)
+	if !refIsDir && len(refs) > 1 {
+		return errors.New("several results need a reference directory")
+	}
+	turnsDir := cfg.ReferenceTurns
+	if turnsDir == "" && refIsDir {
+		turnsDir = cfg.Reference
+	}
+	if turnsDir != "" && !isDir(turnsDir) && len(refs) > 1 {
+		return errors.New("several results need a speakers directory")
+	}
+
+	n := eval.Normalization{KeepCase: cfg.KeepCase, KeepPunctuation: cfg.KeepPunctuation, SpellNumbers: cfg.SpellNumbers}
+
+	var reports []eval.Report
+	for _, ref := range refs {
+		resp, err := loadResult(cfg, ref)
+		if err != nil {
+			return err
+		}
+		if resp.Result == nil {
+			fmt.Fprintf(os.Stderr, "%s: no result, skipped\n", ref)
+			continue
+		}
+
+		names := resultNames(ref, resp)
+
+		refPath := cfg.Reference
+		if refIsDir {
+			if refPath = findReference(cfg.Reference, names, referenceExtensions); refPath == "" {
+				fmt.Fprintf(os.Stderr, "%s: no reference found, skipped\n", ref)
+				continue
+			}
+		}
+		reference, err := eval.LoadReference(refPath)
+		if err != nil {
+			return err
+		}
+
+		turnsPath := turnsDir
+		if isDir(turnsDir) {
+			turnsPath = findReference(turnsDir, names, []string{".rttm"})
+		}
+		if turnsPath != "" {
+			if reference.Turns, err = eval.LoadRTTM(turnsPath); err != nil {
+				return err
+			}
+		}
+
+		report := eval.Evaluate(resp.Result, reference, n)
+		report.Name, report.Reference = names[0], refPath
+		reports = append(reports, report)
+	}
+
+	if len(reports) == 0 {
+		return errors.New("no results were evaluated")
+	}
+
+	if cfg.EvalOutput == "" || cfg.EvalOutput == "-" {
+		return write(os.Stdout, reports)
+	}
+	file, err := os.Create(cfg.EvalOutput)
+	if err != nil {
+		return fmt.Errorf("%s: failed create report file: %w", cfg.EvalOutput, err)
+	}
+	defer file.Close()
+	return write(file, reports)
+}
+
+// Имена для поиска эталона: имя файла результата и имя исходного аудио без расширения
+func resultNames(ref string, resp *prerecorderv2.PreRecorderResultResponse) []string {
+	names := []string{strings.TrimSuffix(filepath.Base(ref), filepath.Ext(ref))}
+	if resp.File != nil && resp.File.Filename != "" {
+		base := filepath.Base(resp.File.Filename)
+		names = append(names, strings.TrimSuffix(base, filepath.Ext(base)))
+	}
+	return names
+}
+
+func findReference(dir string, names []string, extensions []string) string {
+	for _, name := range names {
+		for _, ext := range extensions {
+			path := filepath.Join(dir, name+ext)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -7,6 +7,6 @@ var infoCmd = &cobra.Command{
 	Short: "Find out the status of an audio transcription task.",
 }
 
-func setListFlags() {
+func setInfoFlags() {
 	// пока флагов нет
 }
@@ -1,4 +1,60 @@
 package async
 
-// TODO: флаг -f --filter: completed/in progress/error (по умолчанию все)
-// TODO: флаг -l --limit: количество выводимых данных (по умолчанию 5 )
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go-gladia.io-client/internal/config"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List transcription tasks",
+	Args:  cobra.NoArgs,
+}
+
+func setListFlags(cfg *config.Config) {
+	listCmd.Flags().StringVarP(&cfg.Status, "filter", "f", "", "task status: completed, in progress, error (all by default)")
+	listCmd.Flags().IntVarP(&cfg.Limit, "limit", "l", 5, "number of tasks to show")
+	listCmd.Flags().StringArrayVarP(&cfg.Where, "where", "w", nil, "show tasks with the metadata tag key=value (repeatable)")
+	listCmd.Flags().BoolVar(&cfg.Local, "local", false, "search the local history instead of the server")
+}
+
+type taskRow struct {
+	id       string
+	status   string
+	kind     string
+	fileName string
+	duration float64
+	date     string
+	meta     any
+}
+
+func printTasks(w io.Writer, rows []taskRow) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tID\tSTATUS\tKIND\tFILE\tDURATION\tDATE\tTAGS")
+
+	for idx, row := range rows {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%.1fs\t%s\t%s\n",
+			idx+1, row.id, row.status, row.kind, row.fileName, row.duration, row.date, formatTags(row.meta))
+	}
+	return tw.Flush()
+}
+
+func formatTags(meta any) string {
+	tags, ok := meta.(map[string]any)
+	if !ok {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
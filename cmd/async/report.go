@@ -0,0 +1,165 @@
+package async
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go-gladia.io-client/internal/config"
+	"go-gladia.io-client/internal/sentiment"
+	"go-gladia.io-client/pkg/output"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Analytics reports for transcription results",
+}
+
+var reportSentimentCmd = &cobra.Command{
+	Use:   "sentiment <result.json|task_id|dir>...",
+	Short: "Sentiment and emotion per speaker with a timeline",
+	Long: `Sentiment and emotion per speaker with a timeline.
+
+For every result prints per-speaker aggregates: share of positive, neutral,
+negative and mixed segments, the mean score from -1 (negative) to 1
+(positive), the dominant emotion and the trend from the first to the last
+third of the recording. Sparklines show the score over time, and calls
+where the score drops by 0.5 or more are flagged as escalations.
+
+--timeline writes one csv row per sentiment segment of all results.`,
+	Args: cobra.MinimumNArgs(1),
+}
+
+func setReportFlags(cfg *config.Config) {
+	reportSentimentCmd.Flags().StringVar(&cfg.Timeline, "timeline", "", "write the sentiment timeline csv to this file, - for stdout")
+	reportSentimentCmd.Flags().IntVar(&cfg.SparkWidth, "width", 60, "sparkline width in characters")
+	reportSentimentCmd.Flags().StringVar(&cfg.SpeakerNames, "speaker-names", "", "speaker names as 0=Alice,1=Bob or a yaml file")
+	reportSentimentCmd.Flags().StringVar(&cfg.ChannelNames, "channel-names", "", "label speakers by audio channel as 0=Agent,1=Customer or a yaml file")
+	reportCmd.AddCommand(reportSentimentCmd)
+}
+
+func runReportSentiment(cfg *config.Config, w io.Writer, args []string) error {
+	if cfg.SparkWidth <= 0 {
+		return fmt.Errorf("width must be positive")
+	}
+	opts, err := outputOptions(cfg)
+	if err != nil {
+		return err
+	}
+	refs, err := expandResultRefs(args)
+	if err != nil {
+		return err
+	}
+
+	// при выводе таймлайна в stdout отчет уходит в stderr, чтобы не смешивать его с csv
+	var timeline *sentiment.TimelineWriter
+	switch cfg.Timeline {
+	case "":
+	case "-":
+		timeline, w = sentiment.NewTimelineWriter(os.Stdout), os.Stderr
+	default:
+		file, err := os.Create(cfg.Timeline)
+		if err != nil {
+			return fmt.Errorf("%s: failed create timeline file: %w", cfg.Timeline, err)
+		}
+		defer file.Close()
+		timeline = sentiment.NewTimelineWriter(file)
+	}
+
+	for idx, ref := range refs {
+		resp, err := loadResult(cfg, ref)
+		if err != nil {
+			return err
+		}
+		if resp.Result == nil {
+			return fmt.Errorf("%s: task has no result", ref)
+		}
+		resp, fileOpts := output.ByChannel(resp, opts)
+
+		if idx > 0 {
+			fmt.Fprintln(w)
+		}
+		if len(refs) > 1 {
+			fmt.Fprintf(w, "== %s ==\n", ref)
+		}
+
+		segments := sentiment.Segments(resp.Result)
+		if len(segments) == 0 {
+			fmt.Fprintln(w, "no sentiment analysis in the result")
+			continue
+		}
+		name := func(speaker *int) string { return output.SpeakerName(speaker, fileOpts) }
+
+		if err := printSentiment(w, segments, resp.Result.Metadata.AudioDuration, cfg.SparkWidth, name); err != nil {
+			return err
+		}
+		if timeline != nil {
+			if err := timeline.Write(ref, segments, name); err != nil {
+				return fmt.Errorf("failed write timeline: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func printSentiment(w io.Writer, segments []sentiment.Segment, duration float64, width int, name func(*int) string) error {
+	summaries := sentiment.Summarize(segments, duration)
+	for _, seg := range segments {
+		duration = max(duration, seg.End)
+	}
+	label := func(s sentiment.Summary) string {
+		if s.Speaker == nil {
+			return "All"
+		}
+		return name(s.Speaker)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SPEAKER\tSEGMENTS\tTIME\tPOSITIVE\tNEUTRAL\tNEGATIVE\tMIXED\tSCORE\tTREND\tEMOTION")
+	for _, s := range summaries {
+		trend := "-"
+		if s.HasTrend {
+			trend = fmt.Sprintf("%+.2f", s.Trend())
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%.0f%%\t%.0f%%\t%.0f%%\t%.0f%%\t%+.2f\t%s\t%s\n",
+			label(s), s.Segments, output.FormatClock(s.Duration),
+			s.Share(sentiment.Positive)*100, s.Share(sentiment.Neutral)*100, s.Share(sentiment.Negative)*100, s.Share(sentiment.Mixed)*100,
+			s.Score, trend, s.Emotion)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\nTimeline %s - %s (%s negative, %s neutral, %s positive)\n", output.FormatClock(0), output.FormatClock(duration),
+		sentiment.Sparkline([]float64{-1}), sentiment.Sparkline([]float64{0}), sentiment.Sparkline([]float64{1}))
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, s := range summaries {
+		own := segments
+		if s.Speaker != nil {
+			own = nil
+			for _, seg := range segments {
+				if seg.Speaker != nil && *seg.Speaker == *s.Speaker {
+					own = append(own, seg)
+				}
+			}
+		}
+		fmt.Fprintf(tw, "%s\t|%s|\n", label(s), sentiment.Sparkline(sentiment.Timeline(own, duration, width)))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	var escalations []string
+	for _, s := range summaries {
+		if s.Escalation() {
+			escalations = append(escalations, fmt.Sprintf("%s %+.2f -> %+.2f", label(s), s.First, s.Last))
+		}
+	}
+	if len(escalations) > 0 {
+		fmt.Fprintf(w, "\nEscalation: %s\n", strings.Join(escalations, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package async
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+	"go-gladia.io-client/internal/config"
+	"go-gladia.io-client/internal/repo"
+	"go-gladia.io-client/pkg/output"
+)
+
+// Загрузить сохраненный результат: путь к json файлу или id задачи из локальной истории
+func loadResult(cfg *config.Config, ref string) (*prerecorderv2.PreRecorderResultResponse, error) {
+	resp, err := readResult(cfg, ref)
+	if err != nil {
+		return nil, err
+	}
+	if err := resp.Result.PartialError(); err != nil {
+		cmdLog.Warn("incomplete result", "ref", ref, "error", err)
+	}
+	return resp, nil
+}
+
+func readResult(cfg *config.Config, ref string) (*prerecorderv2.PreRecorderResultResponse, error) {
+	data, err := os.ReadFile(ref)
+	if errors.Is(err, os.ErrNotExist) {
+		history, err := repo.NewFilesRepo(cfg.HistoryDir)
+		if err != nil {
+			return nil, err
+		}
+		return history.Get(ref)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var resp prerecorderv2.PreRecorderResultResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("%s: failed parse result: %w", ref, err)
+	}
+
+	// файл может содержать только объект result без обертки задачи
+	if resp.Result == nil {
+		var res prerecorderv2.Result
+		if err := json.Unmarshal(data, &res); err != nil {
+			return nil, fmt.Errorf("%s: failed parse result: %w", ref, err)
+		}
+		resp.Result = &res
+	}
+	return &resp, nil
+}
+
+// Раскрыть каталоги в список json файлов с результатами, остальные ссылки оставить как есть
+func expandResultRefs(refs []string) ([]string, error) {
+	var expanded []string
+	for _, ref := range refs {
+		info, err := os.Stat(ref)
+		if err != nil || !info.IsDir() {
+			expanded = append(expanded, ref)
+			continue
+		}
+
+		files, err := filepath.Glob(filepath.Join(ref, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed list results: %w", ref, err)
+		}
+		sort.Strings(files)
+		expanded = append(expanded, files...)
+	}
+	return expanded, nil
+}
+
+// Настройки рендеринга из флагов
+func outputOptions(cfg *config.Config) (output.Options, error) {
+	names, err := output.ParseSpeakerNames(cfg.SpeakerNames)
+	if err != nil {
+		return output.Options{}, err
+	}
+
+	channelNames, err := output.ParseSpeakerNames(cfg.ChannelNames)
+	if err != nil {
+		return output.Options{}, err
+	}
+
+	var sheet *output.StyleSheet
+	if cfg.StyleSheet != "" {
+		if sheet, err = output.LoadStyleSheet(cfg.StyleSheet); err != nil {
+			return output.Options{}, err
+		}
+	}
+
+	frameRate, err := output.ParseFrameRate(cfg.FrameRate)
+	if err != nil {
+		return output.Options{}, err
+	}
+
+	// имя исходного файла из --source подходит для плеера, если файл лежит рядом
+	audioPath := cfg.AudioPath
+	if audioPath == "" && cfg.AudioFile != "" {
+		if info, err := os.Stat(cfg.AudioFile); err == nil && !info.IsDir() {
+			audioPath = cfg.AudioFile
+		}
+	}
+
+	return output.Options{
+		MaxLineLength: cfg.MaxLineLength,
+		MaxLines:      cfg.MaxLines,
+		SpeakerLabels: cfg.SpeakerLabels,
+		SpeakerNames:  names,
+		ChannelNames:  channelNames,
+		Bilingual:     cfg.Bilingual,
+		WordTimings:   cfg.WordTimings,
+		LowConfidence: cfg.LowConfidence,
+		StyleSheet:    sheet,
+		FrameRate:     frameRate,
+		Rows:          cfg.Rows,
+		AudioPath:     audioPath,
+		LineNumbers:   cfg.LineNumbers,
+	}, nil
+}
+
+// Флаги переводов проверяются до создания задачи: перевод, которого нет в --translate, в результат не попадет
+func checkTranslationFlags(cfg *config.Config) error {
+	format := cfg.Format
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(cfg.OutputFile), ".")
+	}
+	toStdout := cfg.OutputFile == "" || cfg.OutputFile == "-"
+	if len(cfg.Translations) > 0 && (toStdout || output.IsTable(format)) {
+		return fmt.Errorf("--translations needs an output file and a non-table format")
+	}
+
+	langs := cfg.Translations
+	if cfg.Bilingual != "" {
+		langs = append(slices.Clone(langs), cfg.Bilingual)
+	}
+	for _, lang := range langs {
+		if strings.EqualFold(lang, "all") {
+			if len(cfg.TargetLanguages) == 0 {
+				return fmt.Errorf("--translations all needs --translate")
+			}
+			continue
+		}
+		if !slices.ContainsFunc(cfg.TargetLanguages, func(l string) bool { return strings.EqualFold(l, lang) }) {
+			return fmt.Errorf("translation to %q is not requested, add it to --translate", lang)
+		}
+	}
+	return nil
+}
+
+/*
+# Записать переводы рядом с основным файлом
+
+Каждый язык из --translations пишется в том же формате в файл
+result.<язык>.<расширение>, all - все переводы результата. Файл перевода
+содержит только перевод, двуязычный режим относится к основному файлу.
+*/
+func dumpTranslations(cfg *config.Config, resp *prerecorderv2.PreRecorderResultResponse, filePath, format string, opts output.Options) error {
+	if len(cfg.Translations) == 0 {
+		return nil
+	}
+
+	langs := cfg.Translations
+	if len(langs) == 1 && strings.EqualFold(langs[0], "all") {
+		if langs = output.TranslationLanguages(resp.Result); len(langs) == 0 {
+			return fmt.Errorf("result has no translations, start the task with --translate")
+		}
+	}
+
+	opts.Bilingual = ""
+	for _, lang := range langs {
+		translated, err := output.Translated(resp, lang)
+		if err != nil {
+			return err
+		}
+		if err := uc.Dump(translated, output.TranslationPath(filePath, lang), format, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package async
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+	"go-gladia.io-client/internal/config"
+	"go-gladia.io-client/internal/postprocess"
+	"go-gladia.io-client/pkg/output"
+)
+
+var retimeCmd = &cobra.Command{
+	Use:   "retime <result.json|task_id>...",
+	Short: "Shift, scale, trim or merge transcription timings",
+	Long: `Shift, scale, trim or merge transcription timings to match edited media.
+
+Several results are merged into one timeline in the given order, each part
+starting where the previous one ends. Then --cut and --keep remove ranges
+(in the merged timeline), --scale multiplies timings and --offset shifts
+them. Times are seconds (90, 12.5) or timecodes (1:30, 00:01:30.5).
+
+With --channels the results are channels of one recording (see upload
+--split-channels): they are not shifted but interleaved by time, result N
+becoming channel N-1, and --channel-names labels speakers by channel.`,
+	Args: cobra.MinimumNArgs(1),
+}
+
+func setRetimeFlags(cfg *config.Config) {
+	retimeCmd.Flags().StringVar(&cfg.Offset, "offset", "", "shift timings by this time, negative to move earlier")
+	retimeCmd.Flags().Float64Var(&cfg.Scale, "scale", 1, "multiply timings, e.g. 0.8 for media sped up 1.25x")
+	retimeCmd.Flags().StringArrayVar(&cfg.Cut, "cut", nil, "remove a range START-END and close the gap (repeatable)")
+	retimeCmd.Flags().StringVar(&cfg.KeepRange, "keep", "", "keep only the range START-END")
+	retimeCmd.Flags().StringVar(&cfg.SpeakersMode, "speakers", postprocess.SpeakersSame, "merged speakers: same (equal ids are one person) or separate")
+	retimeCmd.Flags().StringArrayVar(&cfg.SpeakerMap, "speaker-map", nil, "renumber speakers of a part before merging, PART:FROM=TO,... with parts from 1 (repeatable)")
+	retimeCmd.Flags().BoolVar(&cfg.AsChannels, "channels", false, "results are channels of one recording, interleave them instead of appending")
+	retimeCmd.Flags().StringVar(&cfg.ChannelNames, "channel-names", "", "label speakers by channel as 0=Agent,1=Customer or a yaml file")
+	retimeCmd.Flags().StringVarP(&cfg.RetimeTo, "to", "t", "json", "output format ("+strings.Join(output.Names(), ", ")+")")
+	retimeCmd.Flags().StringVarP(&cfg.RetimeOutput, "output", "o", "", "output file, stdout by default")
+}
+
+func runRetime(cfg *config.Config, args []string) error {
+	formatter, err := output.Get(cfg.RetimeTo)
+	if err != nil {
+		return err
+	}
+	opts, err := outputOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	offset := 0.0
+	if cfg.Offset != "" {
+		if offset, err = postprocess.ParseSeconds(cfg.Offset); err != nil {
+			return err
+		}
+	}
+	if cfg.Scale <= 0 {
+		return fmt.Errorf("scale must be positive")
+	}
+
+	var ranges []postprocess.TimeRange
+	for _, value := range cfg.Cut {
+		r, err := postprocess.ParseTimeRange(value)
+		if err != nil {
+			return err
+		}
+		ranges = append(ranges, r)
+	}
+	if cfg.KeepRange != "" {
+		r, err := postprocess.ParseTimeRange(cfg.KeepRange)
+		if err != nil {
+			return err
+		}
+		// --keep и --cut вырезаются за один проход, поэтому оба задаются в исходной шкале
+		ranges = append(ranges, postprocess.Outside(r)...)
+	}
+
+	speakerMaps, err := parsePartSpeakerMaps(cfg.SpeakerMap, len(args))
+	if err != nil {
+		return err
+	}
+	if cfg.AsChannels && len(speakerMaps) > 0 {
+		return fmt.Errorf("--speaker-map can not be used with --channels, label channels with --channel-names")
+	}
+
+	var resp *prerecorderv2.PreRecorderResultResponse
+	parts := make([]postprocess.Part, 0, len(args))
+	for i, ref := range args {
+		r, err := loadResult(cfg, ref)
+		if err != nil {
+			return err
+		}
+		if r.Result == nil {
+			return fmt.Errorf("%s: task has no result", ref)
+		}
+		if resp == nil {
+			resp = r
+		}
+		parts = append(parts, postprocess.Part{Result: r.Result, Speakers: speakerMaps[i+1]})
+	}
+
+	merged := parts[0].Result
+	if cfg.AsChannels {
+		results := make([]*prerecorderv2.Result, len(parts))
+		for i, part := range parts {
+			results[i] = part.Result
+		}
+		merged = postprocess.Interleave(results)
+	} else if len(parts) > 1 || len(speakerMaps) > 0 {
+		if merged, err = postprocess.Merge(parts, cfg.SpeakersMode); err != nil {
+			return err
+		}
+	}
+
+	postprocess.Cut(merged, ranges)
+	postprocess.Scale(merged, cfg.Scale)
+	postprocess.Shift(merged, offset)
+
+	out := *resp
+	out.Result = merged
+	if resp.File != nil {
+		file := *resp.File
+		file.AudioDuration = merged.Metadata.AudioDuration
+		out.File = &file
+	}
+
+	if cfg.RetimeOutput == "" || cfg.RetimeOutput == "-" {
+		return formatter.Format(os.Stdout, &out, opts)
+	}
+	return uc.Dump(&out, cfg.RetimeOutput, cfg.RetimeTo, opts)
+}
+
+// Переназначения спикеров по номеру части: "2:1=0,0=1"
+func parsePartSpeakerMaps(values []string, parts int) (map[int]map[int]int, error) {
+	maps := map[int]map[int]int{}
+	for _, value := range values {
+		partValue, mapping, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, fmt.Errorf("bad speaker map %q, expected PART:FROM=TO,...", value)
+		}
+		part, err := strconv.Atoi(strings.TrimSpace(partValue))
+		if err != nil || part < 1 || part > parts {
+			return nil, fmt.Errorf("bad speaker map %q: part must be from 1 to %d", value, parts)
+		}
+		m, err := postprocess.ParseSpeakerMap(mapping)
+		if err != nil {
+			return nil, err
+		}
+		maps[part] = m
+	}
+	return maps, nil
+}
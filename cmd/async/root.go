@@ -5,11 +5,24 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	"go-gladia.io-client/internal/audio"
+	"go-gladia.io-client/internal/callback"
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
 	"go-gladia.io-client/internal/config"
+	"go-gladia.io-client/internal/dictionary"
+	"go-gladia.io-client/internal/extraction"
 	"go-gladia.io-client/internal/logic"
+	"go-gladia.io-client/internal/postprocess"
+	"go-gladia.io-client/internal/repo"
 	"go-gladia.io-client/pkg/logger"
+	"go-gladia.io-client/pkg/output"
 )
 
 var rootCmd = &cobra.Command{
@@ -20,6 +33,9 @@ var rootCmd = &cobra.Command{
 	Long:    ``,
 }
 
+// Логгер вспомогательных функций команд (например, loadResult), задается в Execute
+var cmdLog logger.ILogger = logger.NewLogger(logger.INFO)
+
 func Execute(
 	ctx context.Context,
 	cfg *config.Config,
@@ -27,11 +43,34 @@ func Execute(
 	a_uc logic.IAsyncUsecase,
 	s_uc logic.ISyncUsecase,
 ) error {
+	cmdLog = log
 
 	// flags set
 	// rootCmd.PersistentFlags().StringVarP(&cfg.Token, "token", "a", cfg.Token, "gladia api token")
-	setUploadFlags()
+	rootCmd.PersistentFlags().CountVarP(&cfg.Verbose, "verbose", "v", "log api requests and responses (-vv with transcript text)")
+	rootCmd.PersistentFlags().StringVar(&cfg.LogFile, "log-file", cfg.LogFile, "write logs to this file instead of stderr")
+	rootCmd.PersistentFlags().StringVar(&cfg.LogFormat, "log-format", cfg.LogFormat, "log format: text or json")
+	setUploadFlags(cfg)
 	setTranscriptionFlags(cfg)
+	setServeCallbacksFlags(cfg)
+	setListFlags(cfg)
+	setConvertFlags(cfg)
+	setEvalFlags(cfg)
+	setDiffFlags(cfg)
+	setRetimeFlags(cfg)
+	setReportFlags(cfg)
+	setStatsFlags(cfg)
+	setUsageFlags(cfg)
+
+	// логи настраиваются после разбора флагов, данные команд остаются в stdout
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		l, ok := log.(*logger.Logger)
+		if !ok {
+			return nil
+		}
+		level, showText := logger.Verbosity(cfg.LogLevel, cfg.Verbose)
+		return l.Setup(logger.Options{Level: level, Format: cfg.LogFormat, File: cfg.LogFile, ShowText: showText})
+	}
 
 	// set usaceses
 
@@ -59,16 +98,60 @@ func Execute(
 			return errors.New("file does not exist:" + filePath)
 		}
 
+		if err := checkCost(cfg, os.Stderr, filePath, uc.List); err != nil {
+			return err
+		}
+
+		if cfg.SplitChannels {
+			channels, err := audio.SplitWAV(filePath, cfg.SplitDir)
+			if err != nil {
+				return err
+			}
+			for ch, channelPath := range channels {
+				audioURL, err := uc.Upload(channelPath)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Channel %d (%s) Audio Url: %s\n", ch, channelPath, audioURL)
+			}
+			return nil
+		}
+
 		audioURL, err := uc.Upload(filePath)
 		if err != nil {
 			return err
 		}
-		log.Info("Audio Url: ", audioURL)
+		fmt.Println("Audio Url:", audioURL)
 		return nil
 	}
 
 	transcriptionCmd.RunE = func(cmd *cobra.Command, args []string) error {
 		audioURL := args[0]
+
+		redactKinds, err := postprocess.ParsePIIKinds(cfg.Redact)
+		if err != nil {
+			return err
+		}
+		opts, err := outputOptions(cfg)
+		if err != nil {
+			return err
+		}
+
+		if err := checkTranslationFlags(cfg); err != nil {
+			return err
+		}
+
+		// файл извлеченных данных проверяется до создания задачи, чтобы не платить за задачу, результат которой некуда записать
+		var classes []string
+		if cfg.Extract != "" {
+			if classes, err = extraction.LoadClasses(cfg.Extract); err != nil {
+				return err
+			}
+			if err := extraction.CheckOutput(cfg.ExtractOutput, classes); err != nil {
+				return err
+			}
+		}
+
 		resultURL, taskID, err := uc.InitTranscription(*cfg, audioURL)
 		if err != nil {
 			return err
@@ -77,7 +160,57 @@ func Execute(
 		fmt.Println("Result Url:", resultURL)
 		fmt.Println("Task ID:", taskID)
 
-		return nil
+		history, err := repo.NewFilesRepo(cfg.HistoryDir)
+		if err != nil {
+			return err
+		}
+
+		if cfg.CallbackURL != "" {
+			// результат придет на serve-callbacks, запоминаем задачу с метаданными, чтобы фильтры --where находили ее до callback
+			meta, err := audio.CustomMetadata(*cfg, audioURL)
+			if err != nil {
+				return err
+			}
+			return history.Save(&prerecorderv2.PreRecorderResultResponse{
+				ID:         taskID,
+				Status:     "queued",
+				CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+				CustomMeta: meta,
+			})
+		}
+
+		if !cfg.AwaitResults {
+			return nil
+		}
+
+		resp, err := uc.PollingResult(ctx, taskID, cfg.AwaitInterval, cfg.AwaitTimeout)
+		if err != nil {
+			return err
+		}
+
+		if !cfg.ServerSpelling {
+			spelling, err := dictionary.LoadSpellings(cfg.SpellingFile, cfg.Spelling)
+			if err != nil {
+				return err
+			}
+			postprocess.ApplySpelling(resp.Result, spelling)
+		}
+		postprocess.Redact(resp.Result, redactKinds)
+
+		if err := history.Save(resp); err != nil {
+			return err
+		}
+
+		if cfg.Extract != "" {
+			if err := extraction.AppendFile(cfg.ExtractOutput, classes, extraction.NewRecord(resp, classes)); err != nil {
+				return err
+			}
+		}
+
+		if err := uc.Dump(resp, cfg.OutputFile, cfg.Format, opts); err != nil {
+			return err
+		}
+		return dumpTranslations(cfg, resp, cfg.OutputFile, cfg.Format, opts)
 	}
 
 	infoCmd.RunE = func(cmd *cobra.Command, args []string) error {
@@ -92,9 +225,174 @@ func Execute(
 		return nil
 	}
 
+	listCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		where, err := repo.ParseTags(cfg.Where)
+		if err != nil {
+			return err
+		}
+		q := repo.Query{Status: cfg.Status, Where: where, Limit: cfg.Limit}
+
+		var rows []taskRow
+		if cfg.Local {
+			history, err := repo.NewFilesRepo(cfg.HistoryDir)
+			if err != nil {
+				return err
+			}
+			tasks, err := history.Find(q)
+			if err != nil {
+				return err
+			}
+			for _, task := range tasks {
+				row := taskRow{id: task.ID, status: task.Status, date: task.CreatedAt, meta: task.CustomMeta}
+				if task.Kind != nil {
+					row.kind = *task.Kind
+				}
+				if task.File != nil {
+					row.fileName = task.File.Filename
+					row.duration = task.File.AudioDuration
+				}
+				rows = append(rows, row)
+			}
+		} else {
+			items, err := uc.List(q)
+			if err != nil {
+				return err
+			}
+			for _, item := range items {
+				rows = append(rows, taskRow{
+					id:       item.ID,
+					status:   item.Status,
+					kind:     item.Kind,
+					fileName: item.File.Filename,
+					duration: item.File.AudioDuration,
+					date:     item.CreatedAT,
+					meta:     item.CustomMetadata,
+				})
+			}
+		}
+
+		return printTasks(os.Stdout, rows)
+	}
+
+	convertCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		formatter, err := output.Get(cfg.ConvertTo)
+		if err != nil {
+			return err
+		}
+
+		opts, err := outputOptions(cfg)
+		if err != nil {
+			return err
+		}
+
+		refs, err := expandResultRefs(args)
+		if err != nil {
+			return err
+		}
+		toStdout := cfg.ConvertOutput == "" || cfg.ConvertOutput == "-"
+		if len(cfg.Translations) > 0 && (toStdout || len(refs) > 1 || output.IsTable(cfg.ConvertTo)) {
+			return fmt.Errorf("--translations needs a single result, an output file and a non-table format")
+		}
+		if len(refs) > 1 || output.IsTable(cfg.ConvertTo) {
+			return convertBatch(cfg, refs, opts)
+		}
+
+		resp, err := loadResult(cfg, refs[0])
+		if err != nil {
+			return err
+		}
+
+		if toStdout {
+			return formatter.Format(os.Stdout, resp, opts)
+		}
+		if err := uc.Dump(resp, cfg.ConvertOutput, cfg.ConvertTo, opts); err != nil {
+			return err
+		}
+		return dumpTranslations(cfg, resp, cfg.ConvertOutput, cfg.ConvertTo, opts)
+	}
+
+	evalCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runEval(cfg, args)
+	}
+
+	diffCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runDiff(cfg, os.Stdout, args)
+	}
+
+	retimeCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runRetime(cfg, args)
+	}
+
+	reportSentimentCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runReportSentiment(cfg, os.Stdout, args)
+	}
+
+	statsCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runStats(cfg, args)
+	}
+
+	usageCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runUsage(cfg, os.Stdout, uc.List)
+	}
+
+	serveCallbacksCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		redactKinds, err := postprocess.ParsePIIKinds(cfg.Redact)
+		if err != nil {
+			return err
+		}
+		opts, err := outputOptions(cfg)
+		if err != nil {
+			return err
+		}
+		for _, format := range cfg.OutputFormats {
+			if _, err := output.Get(format); err != nil {
+				return err
+			}
+		}
+
+		history, err := repo.NewFilesRepo(cfg.HistoryDir)
+		if err != nil {
+			return err
+		}
+
+		onResult := func(resp *prerecorderv2.PreRecorderResultResponse) error {
+			postprocess.Redact(resp.Result, redactKinds)
+
+			// имя файла результата передается командой start в метаданных задачи
+			name := resp.ID
+			if meta, ok := resp.CustomMeta.(map[string]any); ok {
+				if outputFile, ok := meta["output_file"].(string); ok && outputFile != "" {
+					name = strings.TrimSuffix(filepath.Base(outputFile), filepath.Ext(outputFile))
+				}
+			}
+
+			for _, format := range cfg.OutputFormats {
+				filePath := filepath.Join(cfg.OutputDir, name+"."+format)
+				if err := uc.Dump(resp, filePath, format, opts); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return callback.NewServer(log, cfg.CallbackSecret, history, onResult).ListenAndServe(ctx, cfg.Listen)
+	}
+
 	rootCmd.AddCommand(uploadCmd)
 	rootCmd.AddCommand(transcriptionCmd)
 	rootCmd.AddCommand(infoCmd)
+	rootCmd.AddCommand(serveCallbacksCmd)
+	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(convertCmd)
+	rootCmd.AddCommand(evalCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(retimeCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(usageCmd)
 
 	cobra.CheckErr(rootCmd.Execute())
 
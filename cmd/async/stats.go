@@ -0,0 +1,177 @@
+package async
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go-gladia.io-client/internal/analytics"
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+	"go-gladia.io-client/internal/config"
+	"go-gladia.io-client/internal/repo"
+	"go-gladia.io-client/pkg/output"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [result.json|task_id|dir]...",
+	Short: "Conversation analytics: talk time, interruptions, words per minute",
+	Long: `Conversation analytics from diarized utterances.
+
+Per speaker: talk time and share, turns, words per minute, the longest
+monologue, how many times the speaker interrupted others and filler words.
+Per conversation: silence ratio, overlapping speech and interruptions.
+
+Several results, or --history with the completed tasks of the local history
+(filtered with --where), are aggregated: speakers with the same label are
+combined, so label stereo calls with --channel-names 0=Agent,1=Customer to
+compare agents and customers across calls.`,
+}
+
+func setStatsFlags(cfg *config.Config) {
+	statsCmd.Flags().StringVarP(&cfg.StatsFormat, "format", "f", "table", "report format: table or json")
+	statsCmd.Flags().StringVarP(&cfg.StatsOutput, "output", "o", "", "report file, stdout by default")
+	statsCmd.Flags().StringVar(&cfg.Fillers, "fillers", "", "filler words as um,uh,you know or a file with one per line ("+strings.Join(analytics.DefaultFillers, ", ")+" by default)")
+	statsCmd.Flags().BoolVar(&cfg.History, "history", false, "aggregate the completed tasks of the local history")
+	statsCmd.Flags().StringArrayVarP(&cfg.Where, "where", "w", nil, "with --history, only tasks with the metadata tag key=value (repeatable)")
+	statsCmd.Flags().StringVar(&cfg.SpeakerNames, "speaker-names", "", "speaker names as 0=Alice,1=Bob or a yaml file")
+	statsCmd.Flags().StringVar(&cfg.ChannelNames, "channel-names", "", "label speakers by audio channel as 0=Agent,1=Customer or a yaml file")
+}
+
+func runStats(cfg *config.Config, args []string) error {
+	format := strings.ToLower(cfg.StatsFormat)
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unknown report format %q, available: table, json", cfg.StatsFormat)
+	}
+	if len(args) == 0 && !cfg.History {
+		return errors.New("pass results to analyse or --history")
+	}
+
+	opts, err := outputOptions(cfg)
+	if err != nil {
+		return err
+	}
+	fillers, err := analytics.ParseFillers(cfg.Fillers)
+	if err != nil {
+		return err
+	}
+
+	type named struct {
+		name string
+		resp *prerecorderv2.PreRecorderResultResponse
+	}
+	var results []named
+
+	refs, err := expandResultRefs(args)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		resp, err := loadResult(cfg, ref)
+		if err != nil {
+			return err
+		}
+		if resp.Result == nil {
+			return fmt.Errorf("%s: task has no result", ref)
+		}
+		results = append(results, named{ref, resp})
+	}
+
+	if cfg.History {
+		where, err := repo.ParseTags(cfg.Where)
+		if err != nil {
+			return err
+		}
+		history, err := repo.NewFilesRepo(cfg.HistoryDir)
+		if err != nil {
+			return err
+		}
+		tasks, err := history.Find(repo.Query{Status: "completed", Where: where})
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if task.Result != nil {
+				results = append(results, named{task.ID, task})
+			}
+		}
+	}
+	if len(results) == 0 {
+		return errors.New("no completed tasks to analyse")
+	}
+
+	convs := make([]analytics.Conversation, 0, len(results))
+	for _, r := range results {
+		resp, fileOpts := output.ByChannel(r.resp, opts)
+		label := func(speaker *int) string {
+			if name := output.SpeakerName(speaker, fileOpts); name != "" {
+				return name
+			}
+			return "Unknown"
+		}
+		convs = append(convs, analytics.Analyze(r.name, resp, fillers, label))
+	}
+	total := analytics.Aggregate(convs)
+
+	var w io.Writer = os.Stdout
+	if cfg.StatsOutput != "" && cfg.StatsOutput != "-" {
+		file, err := os.Create(cfg.StatsOutput)
+		if err != nil {
+			return fmt.Errorf("%s: failed create report file: %w", cfg.StatsOutput, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if format == "json" {
+		return analytics.WriteJSON(w, convs, total)
+	}
+	if len(convs) == 1 {
+		return printConversation(w, convs[0])
+	}
+	if err := printConversations(w, convs); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	return printConversation(w, total)
+}
+
+// Разговоры пакета по строке на разговор
+func printConversations(w io.Writer, convs []analytics.Conversation) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONVERSATION\tDURATION\tSILENCE\tOVERLAP\tINTERRUPTIONS\tSPEAKERS")
+	for _, c := range convs {
+		fmt.Fprintf(tw, "%s\t%s\t%.0f%%\t%s\t%d\t%d\n",
+			c.Name, output.FormatClock(c.Duration), c.SilenceRatio*100, output.FormatClock(c.Overlap), c.Interruptions, len(c.Speakers))
+	}
+	return tw.Flush()
+}
+
+func printConversation(w io.Writer, c analytics.Conversation) error {
+	title := c.Name
+	if c.Conversations > 1 {
+		title = fmt.Sprintf("%s (%d conversations)", c.Name, c.Conversations)
+	}
+	fmt.Fprintf(w, "%s: duration %s, silence %.0f%%, overlap %s, interruptions %d\n",
+		title, output.FormatClock(c.Duration), c.SilenceRatio*100, output.FormatClock(c.Overlap), c.Interruptions)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SPEAKER\tTALK\tSHARE\tTURNS\tWORDS\tWPM\tLONGEST MONOLOGUE\tINTERRUPTIONS\tFILLERS\tTOP FILLERS")
+	for _, sp := range c.Speakers {
+		top := analytics.TopFillers(sp.Fillers, 3)
+		for i, filler := range top {
+			top[i] = fmt.Sprintf("%s %d", filler, sp.Fillers[filler])
+		}
+		monologue := output.FormatClock(sp.LongestMonologue)
+		if c.Conversations == 1 && sp.LongestMonologue > 0 {
+			monologue += " at " + output.FormatClock(sp.LongestMonologueAt)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.0f%%\t%d\t%d\t%.0f\t%s\t%d\t%d\t%s\n",
+			sp.Name, output.FormatClock(sp.TalkTime), sp.Share*100, sp.Turns, sp.Words, sp.WPM,
+			monologue, sp.Interruptions, sp.FillerCount, strings.Join(top, ", "))
+	}
+	return tw.Flush()
+}
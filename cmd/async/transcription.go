@@ -1,8 +1,11 @@
 package async
 
 import (
+	"strings"
+
 	"github.com/spf13/cobra"
 	"go-gladia.io-client/internal/config"
+	"go-gladia.io-client/pkg/output"
 )
 
 var transcriptionCmd = &cobra.Command{
@@ -13,4 +16,29 @@ var transcriptionCmd = &cobra.Command{
 func setTranscriptionFlags(cfg *config.Config) {
 	transcriptionCmd.Flags().BoolVarP(&cfg.AwaitResults, "await", "a", false, "wait for the transcription to finish")
 	transcriptionCmd.Flags().StringVarP(&cfg.OutputFile, "output", "o", "./result.txt", "name and path of the file for recording the transcription")
+	transcriptionCmd.Flags().StringVarP(&cfg.Format, "format", "f", "", "result format ("+strings.Join(output.Names(), ", ")+"), detected from the output file extension by default")
+	transcriptionCmd.Flags().BoolVar(&cfg.LineNumbers, "line-numbers", false, "number lines in docx")
+	transcriptionCmd.Flags().StringVar(&cfg.AudioPath, "audio", "", "local audio file or url for the player in md and html reports")
+	transcriptionCmd.Flags().StringVar(&cfg.Rows, "rows", output.RowsUtterance, "rows of csv, tsv and jsonl: utterance or word")
+	transcriptionCmd.Flags().StringVar(&cfg.ChannelNames, "channel-names", "", "label speakers by audio channel as 0=Agent,1=Customer or a yaml file")
+	transcriptionCmd.Flags().StringVar(&cfg.SpeakerNames, "speaker-names", "", "speaker names as 0=Alice,1=Bob or a yaml file")
+	transcriptionCmd.Flags().StringSliceVar(&cfg.TargetLanguages, "translate", nil, "translate the transcription to these languages (fr,de)")
+	transcriptionCmd.Flags().StringSliceVar(&cfg.Translations, "translations", nil, "write translations next to the output file as result.fr.srt (languages or all)")
+	transcriptionCmd.Flags().StringVar(&cfg.Bilingual, "bilingual", "", "show this translation under the original lines in subtitles")
+	transcriptionCmd.Flags().BoolVar(&cfg.Summarization, "summarize", false, "generate a summary of the transcription")
+	transcriptionCmd.Flags().StringVar(&cfg.SummaryType, "summary-type", "general", "summary type (general, bullet_points, concise)")
+	transcriptionCmd.Flags().BoolVar(&cfg.Chapterization, "chapters", false, "split the transcription into chapters")
+	transcriptionCmd.Flags().StringArrayVar(&cfg.Prompts, "prompt", nil, "ask LLM a question about the audio (repeatable)")
+	transcriptionCmd.Flags().BoolVar(&cfg.NamedEntities, "ner", false, "detect named entities")
+	transcriptionCmd.Flags().BoolVar(&cfg.Moderation, "moderation", false, "check the transcription for inappropriate content")
+	transcriptionCmd.Flags().StringVar(&cfg.Vocabulary, "vocabulary", "", "file with custom vocabulary terms, one per line (term;intensity)")
+	transcriptionCmd.Flags().StringVar(&cfg.Spelling, "spelling", "", "yaml file with custom spelling (canonical: [variants])")
+	transcriptionCmd.Flags().BoolVar(&cfg.ServerSpelling, "server-spelling", true, "apply custom spelling on the server, otherwise locally to the result")
+	transcriptionCmd.Flags().StringVar(&cfg.Extract, "extract", "", "yaml file with structured data classes to extract")
+	transcriptionCmd.Flags().StringVar(&cfg.ExtractOutput, "extract-output", "./extracted.csv", "file to append extracted data rows to (.csv or .jsonl)")
+	transcriptionCmd.Flags().StringVar(&cfg.CallbackURL, "callback-url", "", "send the result to this url instead of polling (see serve-callbacks)")
+	transcriptionCmd.Flags().StringArrayVar(&cfg.Meta, "meta", nil, "tag the task with custom metadata key=value (repeatable)")
+	transcriptionCmd.Flags().StringVar(&cfg.AudioFile, "source", "", "original audio file name for the source_file tag, required to tag files uploaded with upload")
+	transcriptionCmd.Flags().StringVar(&cfg.BatchID, "batch-id", cfg.BatchID, "batch id for the batch_id tag")
+	transcriptionCmd.Flags().StringSliceVar(&cfg.Redact, "redact", nil, "mask personal data in the result (person, phone, email, card, all)")
 }
@@ -1,12 +1,155 @@
 package async
 
-import "github.com/spf13/cobra"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go-gladia.io-client/internal/audio"
+	"go-gladia.io-client/internal/billing"
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+	"go-gladia.io-client/internal/config"
+	"go-gladia.io-client/internal/repo"
+	"go-gladia.io-client/pkg/output"
+)
 
 var uploadCmd = &cobra.Command{
-	Use:   "upload",
+	Use:   "upload <file>",
 	Short: "Upload audio file on gladia serv",
+	Args:  cobra.ExactArgs(1),
+	Long: `Upload audio file on gladia serv.
+
+With --split-channels a multi-channel WAV (e.g. a call recording with the
+agent and the customer on separate channels) is split locally into mono
+files name.ch0.wav, name.ch1.wav, ... and each channel is uploaded
+separately. Transcribe the channels, then combine the results with
+retime --channels --channel-names 0=Agent,1=Customer.
+
+Before the upload the billable duration is estimated from the audio file
+(WAV header or ffprobe) and the paid features of the transcription
+configuration plus --features, and the expected cost is printed. The upload
+is refused when the estimate exceeds --max-cost or today's spend plus the
+estimate exceeds --daily-budget, unless --yes is passed. Today's spend
+counts the tasks created today on the server and in the local history,
+queued and processing tasks by their estimate. The limits need prices
+(--price, PRICE_PER_HOUR, ADDON_PRICES): without them the upload is
+refused unless --yes is passed.`,
+}
+
+func setUploadFlags(cfg *config.Config) {
+	uploadCmd.Flags().BoolVar(&cfg.SplitChannels, "split-channels", false, "split a multi-channel WAV into mono files and upload each channel")
+	uploadCmd.Flags().StringVar(&cfg.SplitDir, "split-dir", "", "directory for channel files, next to the source file by default")
+	uploadCmd.Flags().Float64Var(&cfg.MaxCost, "max-cost", cfg.MaxCost, "refuse to upload when the estimated cost is higher")
+	uploadCmd.Flags().Float64Var(&cfg.DailyBudget, "daily-budget", cfg.DailyBudget, "refuse to upload when today's spend plus the estimate is higher")
+	uploadCmd.Flags().StringSliceVar(&cfg.Features, "features", nil, "paid features the transcription will enable, for the estimate (diarization,translation)")
+	uploadCmd.Flags().Float64Var(&cfg.PricePerHour, "price", cfg.PricePerHour, "price per billed audio hour")
+	uploadCmd.Flags().BoolVarP(&cfg.Yes, "yes", "y", false, "upload even when the estimate exceeds the limits")
 }
 
-func setUploadFlags() {
-	// пока флагов нет
+/*
+# Проверка стоимости перед загрузкой
+
+Печатает оценку в w и возвращает ошибку, если оценка выходит за --max-cost
+или дневной бюджет и не передан --yes. Если длительность определить не
+удалось или цены не заданы, загрузка разрешается только без ограничений
+или с --yes: иначе ограничение молча пропускало бы любую загрузку.
+*/
+func checkCost(cfg *config.Config, w io.Writer, filePath string, list func(repo.Query) ([]prerecorderv2.ListItem, error)) error {
+	guarded := cfg.MaxCost > 0 || cfg.DailyBudget > 0
+
+	prices, err := usagePrices(cfg)
+	if err != nil {
+		return err
+	}
+	if guarded && prices.IsZero() {
+		if !cfg.Yes {
+			return errors.New("--max-cost and --daily-budget need prices, set --price or PRICE_PER_HOUR and ADDON_PRICES, or pass --yes to upload without the cost check")
+		}
+		fmt.Fprintln(w, "Warning: no prices are set, the cost limits are not checked, uploading because of --yes")
+		guarded = false
+	}
+
+	probe, err := audio.ProbeFile(filePath)
+	if errors.Is(err, audio.ErrUnknownDuration) {
+		if guarded && !cfg.Yes {
+			return fmt.Errorf("%w, pass --yes to upload without the cost check", err)
+		}
+		fmt.Fprintf(w, "Cost estimate skipped: %v\n", err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	estimate := billing.EstimateCost(probe.Duration, probe.Channels, *cfg, cfg.Features, prices)
+
+	fmt.Fprintf(w, "Estimate: %s audio, %d channel(s), %.2f billed hours (%s)",
+		output.FormatClock(estimate.Duration), estimate.Channels, estimate.Hours, billing.Profile(estimate.Features))
+	if !prices.IsZero() {
+		fmt.Fprintf(w, ", expected cost %s", prices.Format(estimate.Cost))
+	}
+	fmt.Fprintln(w)
+
+	var exceeded []string
+	if guarded && cfg.MaxCost > 0 && estimate.Cost > cfg.MaxCost {
+		exceeded = append(exceeded, fmt.Sprintf("estimated cost %s exceeds --max-cost %s",
+			prices.Format(estimate.Cost), prices.Format(cfg.MaxCost)))
+	}
+	if guarded && cfg.DailyBudget > 0 {
+		spent, err := todaySpend(cfg, prices, list)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Today: %s spent of the daily budget %s\n", prices.Format(spent), prices.Format(cfg.DailyBudget))
+		if spent+estimate.Cost > cfg.DailyBudget {
+			exceeded = append(exceeded, fmt.Sprintf("today's spend %s plus the estimate exceeds the daily budget %s",
+				prices.Format(spent), prices.Format(cfg.DailyBudget)))
+		}
+	}
+
+	if len(exceeded) == 0 {
+		return nil
+	}
+	if cfg.Yes {
+		fmt.Fprintf(w, "Warning: %s, uploading because of --yes\n", exceeded[0])
+		return nil
+	}
+	return fmt.Errorf("%s, pass --yes to upload anyway", exceeded[0])
+}
+
+/*
+# Расходы за сегодня
+
+Задачи, созданные сегодня (UTC), на сервере и в локальной истории:
+завершенные считаются по оплаченному времени, поставленные в очередь и
+обрабатываемые - по длительности файла и включенным функциям. Серверные
+задачи идут первыми: запись в очереди, сохраненная командой transcription
+в режиме callback, не знает длительности файла.
+*/
+func todaySpend(cfg *config.Config, prices billing.Prices, list func(repo.Query) ([]prerecorderv2.ListItem, error)) (float64, error) {
+	now := time.Now().UTC()
+	q := repo.Query{Since: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)}
+
+	items, err := list(q)
+	if err != nil {
+		return 0, err
+	}
+	jobs := make([]billing.Job, 0, len(items))
+	for _, item := range items {
+		jobs = append(jobs, billing.FromListItem(item))
+	}
+
+	history, err := repo.NewFilesRepo(cfg.HistoryDir)
+	if err != nil {
+		return 0, err
+	}
+	tasks, err := history.Find(q)
+	if err != nil {
+		return 0, err
+	}
+	for _, task := range tasks {
+		jobs = append(jobs, billing.FromResult(task))
+	}
+	return billing.DaySpend(billing.Dedup(jobs), prices, now), nil
 }
@@ -0,0 +1,252 @@
+package async
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go-gladia.io-client/internal/billing"
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+	"go-gladia.io-client/internal/config"
+	"go-gladia.io-client/internal/repo"
+	"go-gladia.io-client/pkg/output"
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Billed audio hours and cost by day, week, month, profile or tag",
+	Args:  cobra.NoArgs,
+	Long: `Billed audio hours and cost of completed tasks.
+
+Billing time comes from the result metadata of the local history and of the
+tasks on the server (the latest --limit tasks), tasks found in both are
+counted once. The cost is billed hours times the price per hour plus the
+add-on prices of the features enabled in the task, for example
+--addon-price diarization=0.1,translation=0.2. Prices and the monthly budget
+can also be set with PRICE_PER_HOUR, ADDON_PRICES, CURRENCY, MONTHLY_BUDGET
+and BUDGET_WARN.
+
+--by profile groups tasks by the set of enabled paid features, --by tag:KEY
+by the value of a custom metadata tag.
+
+The monthly budget is checked against all completed tasks of the reported
+months, regardless of --where, --since, --until and --limit, so the server
+task list is fetched page by page back to the first of the earliest month.`,
+}
+
+func setUsageFlags(cfg *config.Config) {
+	usageCmd.Flags().StringVarP(&cfg.UsageBy, "by", "b", billing.ByMonth, "group by day, week, month, profile or tag:KEY")
+	usageCmd.Flags().StringVar(&cfg.UsageSource, "source", "all", "tasks from local history, remote server or all")
+	usageCmd.Flags().IntVar(&cfg.UsageLimit, "limit", 100, "number of latest server tasks to fetch")
+	usageCmd.Flags().StringArrayVarP(&cfg.Where, "where", "w", nil, "only tasks with the metadata tag key=value (repeatable)")
+	usageCmd.Flags().StringVar(&cfg.Since, "since", "", "first day 2006-01-02")
+	usageCmd.Flags().StringVar(&cfg.Until, "until", "", "last day 2006-01-02, inclusive")
+	usageCmd.Flags().Float64Var(&cfg.PricePerHour, "price", cfg.PricePerHour, "price per billed audio hour")
+	usageCmd.Flags().StringSliceVar(&cfg.AddOnPrices, "addon-price", cfg.AddOnPrices, "add-on price per hour as feature=price (repeatable)")
+	usageCmd.Flags().StringVar(&cfg.Currency, "currency", cfg.Currency, "currency of the prices")
+	usageCmd.Flags().Float64Var(&cfg.MonthlyBudget, "budget", cfg.MonthlyBudget, "monthly budget, warn when crossed")
+	usageCmd.Flags().Float64Var(&cfg.BudgetWarn, "budget-warn", cfg.BudgetWarn, "share of the monthly budget to warn at")
+	usageCmd.Flags().StringVar(&cfg.UsageCSV, "csv", "", "export billed tasks to a csv file, - for stdout")
+}
+
+// Цены из конфигурации
+func usagePrices(cfg *config.Config) (billing.Prices, error) {
+	addOns, err := billing.ParseAddOns(cfg.AddOnPrices)
+	if err != nil {
+		return billing.Prices{}, err
+	}
+	if cfg.PricePerHour < 0 {
+		return billing.Prices{}, fmt.Errorf("invalid price per hour %v", cfg.PricePerHour)
+	}
+	return billing.Prices{PerHour: cfg.PricePerHour, AddOns: addOns, Currency: cfg.Currency}, nil
+}
+
+func runUsage(cfg *config.Config, w io.Writer, list func(repo.Query) ([]prerecorderv2.ListItem, error)) error {
+	source := strings.ToLower(cfg.UsageSource)
+	if source != "local" && source != "remote" && source != "all" {
+		return fmt.Errorf("unknown source %q, available: local, remote, all", cfg.UsageSource)
+	}
+	if _, err := billing.GroupKey(billing.Job{}, cfg.UsageBy); err != nil {
+		return err
+	}
+	prices, err := usagePrices(cfg)
+	if err != nil {
+		return err
+	}
+	since, until, err := usagePeriod(cfg.Since, cfg.Until)
+	if err != nil {
+		return err
+	}
+	where, err := repo.ParseTags(cfg.Where)
+	if err != nil {
+		return err
+	}
+	jobs, err := usageJobs(cfg, source, repo.Query{Status: "completed", Where: where, Since: since}, cfg.UsageLimit, list)
+	if err != nil {
+		return err
+	}
+
+	filtered := jobs[:0]
+	for _, job := range jobs {
+		if !since.IsZero() && (job.Created.IsZero() || job.Created.Before(since)) {
+			continue
+		}
+		if !until.IsZero() && (job.Created.IsZero() || !job.Created.Before(until)) {
+			continue
+		}
+		filtered = append(filtered, job)
+	}
+	jobs = filtered
+
+	rows, err := billing.Group(jobs, cfg.UsageBy, prices)
+	if err != nil {
+		return err
+	}
+
+	if cfg.UsageCSV != "" {
+		if err := writeUsageCSV(cfg.UsageCSV, jobs, prices); err != nil {
+			return err
+		}
+		if cfg.UsageCSV == "-" {
+			// данные для бухгалтерии идут в stdout, сводка - в stderr
+			w = os.Stderr
+		}
+	}
+
+	if err := printUsage(w, cfg.UsageBy, rows, prices); err != nil {
+		return err
+	}
+
+	budgets, err := usageBudgets(cfg, source, jobs, prices, list)
+	if err != nil {
+		return err
+	}
+	for _, b := range budgets {
+		state := "reached"
+		if b.Exceeded() {
+			state = "exceeded"
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s spend %s is %.0f%% of the monthly budget %s (%s)\n",
+			b.Month, prices.Format(b.Spent), b.Spent/b.Limit*100, prices.Format(b.Limit), state)
+	}
+	return nil
+}
+
+// Завершенные задачи локальной истории и сервера без повторов, с сервера не больше remoteLimit (0 - все задачи с q.Since)
+func usageJobs(cfg *config.Config, source string, q repo.Query, remoteLimit int, list func(repo.Query) ([]prerecorderv2.ListItem, error)) ([]billing.Job, error) {
+	var jobs []billing.Job
+	if source != "remote" {
+		history, err := repo.NewFilesRepo(cfg.HistoryDir)
+		if err != nil {
+			return nil, err
+		}
+		tasks, err := history.Find(q)
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			jobs = append(jobs, billing.FromResult(task))
+		}
+	}
+	if source != "local" {
+		q.Limit = remoteLimit
+		items, err := list(q)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			jobs = append(jobs, billing.FromListItem(item))
+		}
+	}
+	return billing.Dedup(jobs), nil
+}
+
+/*
+# Месячный бюджет по месяцам отчета
+
+Расход месяца не зависит от фильтров отчета: задачи всех месяцев, которые
+попали в отчет, загружаются заново без --where и --limit, начиная с первого
+числа самого раннего месяца.
+*/
+func usageBudgets(cfg *config.Config, source string, reported []billing.Job, prices billing.Prices, list func(repo.Query) ([]prerecorderv2.ListItem, error)) ([]billing.Budget, error) {
+	if cfg.MonthlyBudget <= 0 {
+		return nil, nil
+	}
+
+	months := map[string]bool{}
+	var from time.Time
+	for _, job := range reported {
+		if job.Created.IsZero() {
+			continue
+		}
+		months[job.Created.Format("2006-01")] = true
+		if start := time.Date(job.Created.Year(), job.Created.Month(), 1, 0, 0, 0, 0, job.Created.Location()); from.IsZero() || start.Before(from) {
+			from = start
+		}
+	}
+	if len(months) == 0 {
+		return nil, nil
+	}
+
+	all, err := usageJobs(cfg, source, repo.Query{Status: "completed", Since: from}, 0, list)
+	if err != nil {
+		return nil, err
+	}
+	inMonths := all[:0]
+	for _, job := range all {
+		if !job.Created.IsZero() && months[job.Created.Format("2006-01")] {
+			inMonths = append(inMonths, job)
+		}
+	}
+	return billing.CheckBudget(inMonths, prices, cfg.MonthlyBudget, cfg.BudgetWarn), nil
+}
+
+// Период отчета: since включительно, until - до конца указанного дня
+func usagePeriod(sinceValue, untilValue string) (since, until time.Time, err error) {
+	if sinceValue != "" {
+		if since, err = time.Parse(time.DateOnly, sinceValue); err != nil {
+			return since, until, fmt.Errorf("invalid --since %q, expected 2006-01-02", sinceValue)
+		}
+	}
+	if untilValue != "" {
+		if until, err = time.Parse(time.DateOnly, untilValue); err != nil {
+			return since, until, fmt.Errorf("invalid --until %q, expected 2006-01-02", untilValue)
+		}
+		until = until.AddDate(0, 0, 1)
+	}
+	return since, until, nil
+}
+
+func writeUsageCSV(path string, jobs []billing.Job, prices billing.Prices) error {
+	if path == "-" {
+		return billing.WriteCSV(os.Stdout, jobs, prices)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("%s: failed create csv file: %w", path, err)
+	}
+	defer file.Close()
+
+	if err := billing.WriteCSV(file, jobs, prices); err != nil {
+		return fmt.Errorf("%s: failed write csv: %w", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "%d tasks saved to: %s\n", len(jobs), path)
+	return nil
+}
+
+func printUsage(w io.Writer, by string, rows []billing.Row, prices billing.Prices) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tJOBS\tAUDIO\tBILLED\tHOURS\tPROCESSING\tCOST\n", strings.ToUpper(by))
+
+	for _, row := range append(rows, billing.Total(rows)) {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%.2f\t%s\t%s\n",
+			row.Key, row.Jobs, output.FormatClock(row.AudioDuration), output.FormatClock(row.BillingTime),
+			row.Hours(), output.FormatClock(row.TranscriptionTime), prices.Format(row.Cost))
+	}
+	return tw.Flush()
+}
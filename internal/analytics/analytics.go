@@ -0,0 +1,252 @@
+package analytics
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+	"go-gladia.io-client/pkg/output"
+)
+
+// Насколько новая реплика должна наложиться на чужую, чтобы считаться перебиванием, в секундах
+const InterruptionMinOverlap = 0.5
+
+// Показатели спикера
+type Speaker struct {
+	Name               string         `json:"name"`
+	TalkTime           float64        `json:"talk_time"`
+	Share              float64        `json:"share"` // доля от общего времени речи, 0-1
+	Turns              int            `json:"turns"`
+	Words              int            `json:"words"`
+	WPM                float64        `json:"words_per_minute"`
+	LongestMonologue   float64        `json:"longest_monologue"`
+	LongestMonologueAt float64        `json:"longest_monologue_at"`
+	Interruptions      int            `json:"interruptions"` // сколько раз спикер перебил других
+	Fillers            map[string]int `json:"fillers"`
+	FillerCount        int            `json:"filler_count"`
+}
+
+// Показатели разговора; в сводке по нескольким разговорам Name = TOTAL
+type Conversation struct {
+	Name          string    `json:"name"`
+	TaskID        string    `json:"task_id,omitempty"`
+	Conversations int       `json:"conversations"`
+	Duration      float64   `json:"duration"`
+	Speech        float64   `json:"speech"`  // время, когда говорит хотя бы один спикер
+	Overlap       float64   `json:"overlap"` // время, когда говорят одновременно
+	Silence       float64   `json:"silence"`
+	SilenceRatio  float64   `json:"silence_ratio"`
+	Interruptions int       `json:"interruptions"`
+	Speakers      []Speaker `json:"speakers"`
+}
+
+/*
+# Показатели разговора
+
+Время речи, реплики и монологи считаются так же, как в отчетах md/html:
+реплика - подряд идущие высказывания одного спикера. Тишина и наложения
+считаются по объединению интервалов высказываний. Перебивание - начало
+высказывания, пока высказывание другого спикера продолжается еще не меньше
+InterruptionMinOverlap. label - подпись спикера, по ней же спикеры
+объединяются в сводке по нескольким разговорам.
+*/
+func Analyze(name string, resp *prerecorderv2.PreRecorderResultResponse, fillers Fillers, label func(*int) string) Conversation {
+	c := Conversation{Name: name, TaskID: resp.ID, Conversations: 1}
+	res := resp.Result
+	if res == nil {
+		return c
+	}
+
+	utterances := append([]prerecorderv2.Utterance(nil), res.Transcription.Utterances...)
+	sort.SliceStable(utterances, func(i, j int) bool { return utterances[i].Start < utterances[j].Start })
+
+	c.Speech, c.Overlap = coverage(utterances)
+	c.Duration = max(res.Metadata.AudioDuration, c.Speech)
+	for _, u := range utterances {
+		c.Duration = max(c.Duration, u.End)
+	}
+	c.Silence = max(0, c.Duration-c.Speech)
+	if c.Duration > 0 {
+		c.SilenceRatio = c.Silence / c.Duration
+	}
+
+	// спикеры с одинаковой подписью, например по --channel-names, считаются одним
+	byLabel := map[string]*Speaker{}
+	var order []string
+	for _, st := range output.SpeakerTalkTime(res) {
+		name := label(st.Speaker)
+		sp, ok := byLabel[name]
+		if !ok {
+			sp = &Speaker{Name: name, Fillers: map[string]int{}}
+			byLabel[name] = sp
+			order = append(order, name)
+		}
+		sp.TalkTime += st.Duration
+		sp.Turns += st.Turns
+		sp.Words += st.Words
+	}
+	get := func(speaker *int) *Speaker {
+		return byLabel[label(speaker)]
+	}
+
+	for _, turn := range output.Dialogue(res) {
+		if sp := get(turn.Speaker); sp != nil && turn.End-turn.Start > sp.LongestMonologue {
+			sp.LongestMonologue, sp.LongestMonologueAt = turn.End-turn.Start, turn.Start
+		}
+	}
+	for _, u := range utterances {
+		if sp := get(u.Speaker); sp != nil {
+			for filler, n := range fillers.Count(u.Text) {
+				sp.Fillers[filler] += n
+			}
+		}
+	}
+
+	// последний конец высказывания каждого спикера среди уже начавшихся
+	lastEnd := map[int]float64{}
+	for _, u := range utterances {
+		if u.Speaker == nil {
+			continue
+		}
+		for other, end := range lastEnd {
+			if other != *u.Speaker && end-u.Start >= InterruptionMinOverlap {
+				get(u.Speaker).Interruptions++
+				c.Interruptions++
+				break
+			}
+		}
+		lastEnd[*u.Speaker] = max(lastEnd[*u.Speaker], u.End)
+	}
+
+	for _, name := range order {
+		c.Speakers = append(c.Speakers, *byLabel[name])
+	}
+	finish(&c)
+	return c
+}
+
+// Время речи (объединение интервалов) и время одновременной речи
+func coverage(sorted []prerecorderv2.Utterance) (speech, overlap float64) {
+	type event struct {
+		at    float64
+		delta int
+	}
+	events := make([]event, 0, len(sorted)*2)
+	for _, u := range sorted {
+		if u.End > u.Start {
+			events = append(events, event{u.Start, 1}, event{u.End, -1})
+		}
+	}
+	// при равном времени конец раньше начала, чтобы стык высказываний не считался наложением
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].at != events[j].at {
+			return events[i].at < events[j].at
+		}
+		return events[i].delta < events[j].delta
+	})
+
+	active := 0
+	prev := 0.0
+	for _, e := range events {
+		if active >= 1 {
+			speech += e.at - prev
+		}
+		if active >= 2 {
+			overlap += e.at - prev
+		}
+		active += e.delta
+		prev = e.at
+	}
+	return speech, overlap
+}
+
+// Производные показатели: доли, темп речи, слова-паразиты
+func finish(c *Conversation) {
+	total := 0.0
+	for _, sp := range c.Speakers {
+		total += sp.TalkTime
+	}
+	for i := range c.Speakers {
+		sp := &c.Speakers[i]
+		if total > 0 {
+			sp.Share = sp.TalkTime / total
+		}
+		if sp.TalkTime > 0 {
+			sp.WPM = float64(sp.Words) / (sp.TalkTime / 60)
+		}
+		sp.FillerCount = 0
+		for _, n := range sp.Fillers {
+			sp.FillerCount += n
+		}
+	}
+	sort.SliceStable(c.Speakers, func(i, j int) bool { return c.Speakers[i].TalkTime > c.Speakers[j].TalkTime })
+}
+
+// Сводка по нескольким разговорам: времена и счетчики суммируются, спикеры объединяются по подписи
+func Aggregate(convs []Conversation) Conversation {
+	total := Conversation{Name: "TOTAL"}
+	byName := map[string]int{}
+
+	for _, c := range convs {
+		total.Conversations += c.Conversations
+		total.Duration += c.Duration
+		total.Speech += c.Speech
+		total.Overlap += c.Overlap
+		total.Silence += c.Silence
+		total.Interruptions += c.Interruptions
+
+		for _, sp := range c.Speakers {
+			idx, ok := byName[sp.Name]
+			if !ok {
+				idx = len(total.Speakers)
+				byName[sp.Name] = idx
+				total.Speakers = append(total.Speakers, Speaker{Name: sp.Name, Fillers: map[string]int{}})
+			}
+			dst := &total.Speakers[idx]
+			dst.TalkTime += sp.TalkTime
+			dst.Turns += sp.Turns
+			dst.Words += sp.Words
+			dst.Interruptions += sp.Interruptions
+			if sp.LongestMonologue > dst.LongestMonologue {
+				dst.LongestMonologue, dst.LongestMonologueAt = sp.LongestMonologue, sp.LongestMonologueAt
+			}
+			for filler, n := range sp.Fillers {
+				dst.Fillers[filler] += n
+			}
+		}
+	}
+	if total.Duration > 0 {
+		total.SilenceRatio = total.Silence / total.Duration
+	}
+	finish(&total)
+	return total
+}
+
+// Записать показатели в json: разговоры и сводка
+func WriteJSON(w io.Writer, convs []Conversation, total Conversation) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Conversations []Conversation `json:"conversations"`
+		Total         Conversation   `json:"total"`
+	}{convs, total})
+}
+
+// Самые частые слова-паразиты, при равенстве по алфавиту
+func TopFillers(fillers map[string]int, n int) []string {
+	names := make([]string, 0, len(fillers))
+	for name := range fillers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if fillers[names[i]] != fillers[names[j]] {
+			return fillers[names[i]] > fillers[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > n {
+		names = names[:n]
+	}
+	return names
+}
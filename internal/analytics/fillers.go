@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"go-gladia.io-client/internal/eval"
+)
+
+// Слова-паразиты по умолчанию, английские. Только однозначные: "like", "actually"
+// и подобные чаще несут смысл, их можно добавить через --fillers
+var DefaultFillers = []string{"um", "uh", "er", "ah", "hmm", "you know", "i mean"}
+
+// Слова-паразиты, каждая фраза - нормализованные слова
+type Fillers [][]string
+
+func NewFillers(phrases []string) Fillers {
+	var f Fillers
+	for _, phrase := range phrases {
+		if words := eval.Normalize(phrase, eval.Normalization{}); len(words) > 0 {
+			f = append(f, words)
+		}
+	}
+	return f
+}
+
+/*
+# Разобрать список слов-паразитов
+
+Значение - список через запятую ("um,uh,you know") или путь к файлу с
+фразой на строку, пустое - список по умолчанию.
+*/
+func ParseFillers(value string) (Fillers, error) {
+	if value == "" {
+		return NewFillers(DefaultFillers), nil
+	}
+	if strings.Contains(value, ",") {
+		return NewFillers(strings.Split(value, ",")), nil
+	}
+
+	file, err := os.Open(value)
+	if os.IsNotExist(err) {
+		// одно слово без запятой
+		return NewFillers([]string{value}), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%s: failed read fillers: %w", value, err)
+	}
+	defer file.Close()
+
+	var phrases []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			phrases = append(phrases, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: failed read fillers: %w", value, err)
+	}
+	return NewFillers(phrases), nil
+}
+
+// Количество вхождений каждой фразы в тексте, более длинные фразы проверяются первыми
+func (f Fillers) Count(text string) map[string]int {
+	words := eval.Normalize(text, eval.Normalization{})
+	counts := map[string]int{}
+
+	for i := 0; i < len(words); {
+		matched := 0
+		for _, phrase := range f {
+			if len(phrase) > matched && i+len(phrase) <= len(words) && equalWords(words[i:i+len(phrase)], phrase) {
+				matched = len(phrase)
+			}
+		}
+		if matched == 0 {
+			i++
+			continue
+		}
+		counts[strings.Join(words[i:i+matched], " ")]++
+		i += matched
+	}
+	return counts
+}
+
+func equalWords(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -6,6 +6,8 @@ import (
 
 	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
 	"go-gladia.io-client/internal/config"
+	"go-gladia.io-client/internal/repo"
+	"go-gladia.io-client/pkg/output"
 )
 
 type (
@@ -17,11 +19,11 @@ type (
 		// Информация о статусе задачи
 		Info(taskID string) (*prerecorderv2.Result, error)
 		// Ожидать результат
-		PollingResult(ctx context.Context, taskID string, timeInterval time.Duration, timeout time.Duration) (*prerecorderv2.Result, error)
+		PollingResult(ctx context.Context, taskID string, timeInterval time.Duration, timeout time.Duration) (*prerecorderv2.PreRecorderResultResponse, error)
 		// Список загруженных на сервер задач
-		List() error
+		List(q repo.Query) ([]prerecorderv2.ListItem, error)
 		// Сдампить результат в файл
-		Dump() error
+		Dump(resp *prerecorderv2.PreRecorderResultResponse, filePath string, format string, opts output.Options) error
 	}
 
 	AudioRecorder interface {
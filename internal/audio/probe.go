@@ -0,0 +1,78 @@
+package audio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+var ErrUnknownDuration = errors.New("unknown audio duration")
+
+// Длительность и количество каналов локального файла
+type Probe struct {
+	Duration float64 // секунды
+	Channels int
+}
+
+/*
+# Определить длительность аудио файла
+
+WAV разбирается по заголовку. Для остальных форматов используется ffprobe,
+если он установлен, иначе возвращается ErrUnknownDuration.
+*/
+func ProbeFile(path string) (Probe, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Probe{}, fmt.Errorf("%s: failed open audio: %w", path, err)
+	}
+	defer f.Close()
+
+	format, dataSize, err := readWAVHeader(f)
+	if err == nil {
+		dataSize = wavDataSize(f, dataSize)
+		bytesPerSecond := float64(format.SampleRate) * float64(format.BlockAlign)
+		if bytesPerSecond == 0 {
+			return Probe{}, fmt.Errorf("%s: %w", path, ErrUnknownDuration)
+		}
+		return Probe{Duration: float64(dataSize) / bytesPerSecond, Channels: int(format.Channels)}, nil
+	}
+
+	return ffprobe(path)
+}
+
+func ffprobe(path string) (Probe, error) {
+	bin, err := exec.LookPath("ffprobe")
+	if err != nil {
+		return Probe{}, fmt.Errorf("%s: %w: not a WAV file and ffprobe is not installed", path, ErrUnknownDuration)
+	}
+
+	out, err := exec.Command(bin, "-v", "error", "-show_entries", "format=duration:stream=channels", "-of", "json", path).Output()
+	if err != nil {
+		return Probe{}, fmt.Errorf("%s: %w: ffprobe failed: %v", path, ErrUnknownDuration, err)
+	}
+
+	var info struct {
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+		Streams []struct {
+			Channels int `json:"channels"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return Probe{}, fmt.Errorf("%s: %w: bad ffprobe output", path, ErrUnknownDuration)
+	}
+	duration, err := strconv.ParseFloat(info.Format.Duration, 64)
+	if err != nil {
+		return Probe{}, fmt.Errorf("%s: %w", path, ErrUnknownDuration)
+	}
+
+	probe := Probe{Duration: duration, Channels: 1}
+	for _, stream := range info.Streams {
+		probe.Channels = max(probe.Channels, stream.Channels)
+	}
+	return probe, nil
+}
@@ -7,11 +7,18 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"time"
 
 	http_client "go-gladia.io-client/internal/clients/http"
 	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
 	"go-gladia.io-client/internal/config"
+	"go-gladia.io-client/internal/dictionary"
+	"go-gladia.io-client/internal/extraction"
+	"go-gladia.io-client/internal/repo"
+	"go-gladia.io-client/pkg/logger"
 	"go-gladia.io-client/pkg/output"
 )
 
@@ -22,14 +29,19 @@ const (
 	TXT  = FileType(".txt")
 )
 
+const (
+	defaultListLimit = 5
+	maxListLimit     = 100
+)
+
 type AudoUploader struct {
-	l          output.IOutput
+	log        logger.ILogger
 	httpClient http_client.IHttpClient
 }
 
-func New(l output.IOutput, client http_client.IHttpClient) (*AudoUploader, error) {
+func New(log logger.ILogger, client http_client.IHttpClient) (*AudoUploader, error) {
 	r := &AudoUploader{
-		l:          l,
+		log:        log,
 		httpClient: client,
 	}
 
@@ -39,76 +51,208 @@ func New(l output.IOutput, client http_client.IHttpClient) (*AudoUploader, error
 // Загрузить аудио файл на сервер gladia
 func (uc *AudoUploader) Upload(filePath string) error {
 	// открыть audio file
-	uc.l.Printf("Try read file from path: %s\n", filePath)
+	uc.log.Debug("read audio file", "path", filePath)
 
 	file, err := os.Open(filePath)
 	if err != nil {
-		uc.l.Printf("file read error %s: %w\n", filePath, err)
 		return fmt.Errorf("%s: file read error %w", filePath, err)
 	}
 	defer file.Close()
 
-	uc.l.Print("Open file done")
-
 	// загрузить файл
 	resp, err := uc.httpClient.AudioUploadFromFile(file)
 
 	// обработка ответа от сервера
 	if err != nil {
-		uc.l.Print("upload error:", err)
 		return err
 	}
 
 	audioURL := resp.AudioUrl
 
-	uc.l.Print("File upload done!")
-	uc.l.Print("Audo Url:", audioURL)
+	uc.log.Info("file uploaded", "path", filePath, "audio_url", audioURL)
 
 	metaData, err := json.Marshal(resp.MetaData)
 	if err != nil {
 		return err
 	}
-	uc.l.Printf("Meta Data: %s\n", metaData)
+	uc.log.Debug("upload metadata", "metadata", string(metaData))
 
 	return nil
 }
 
 // Выполнить асинхронный запрос к сервису на транскрибацию и получить task_id
 func (uc *AudoUploader) InitTranscription(cfg config.Config, audioURL string) (string, string, error) {
-	var url *url.URL
-	var err error
-
-	if url, err = url.Parse(audioURL); err != nil {
-		uc.l.Print("error: init transcription: gladia file url is not valid:", err)
-		return "", "", err
+	fileURL, err := url.Parse(audioURL)
+	if err != nil {
+		return "", "", fmt.Errorf("init transcription: gladia file url is not valid: %w", err)
 	}
 
 	body := &prerecorderv2.PreRecorderBody{
-		AudioUrl:    url.String(),
+		AudioUrl:    fileURL.String(),
 		Diarization: cfg.Diarization,
 		LangConf: &prerecorderv2.LanguageConf{
 			Languages:     cfg.InputLanguages,
 			CodeSwitching: false,
 		},
-		Translation: cfg.Translation,
+		Translation: cfg.Translation || len(cfg.TargetLanguages) > 0,
 		TranslationConf: &prerecorderv2.TranslationConf{
 			TargetLanguages: cfg.TargetLanguages,
 		},
 		Subtitle:          false,
 		SubtitlesConf:     &prerecorderv2.SubtitlesConf{},
 		SentimentAnalysis: true,
+		Summarization:     cfg.Summarization,
+		Chapterization:    cfg.Chapterization,
+		// сущности нужны для локального скрытия персональных данных
+		NamedEntities: cfg.NamedEntities || len(cfg.Redact) > 0,
+		Moderation:    cfg.Moderation,
+	}
+
+	if cfg.Summarization && cfg.SummaryType != "" {
+		body.SummarizationConf = &prerecorderv2.SummarizationConf{
+			Type: cfg.SummaryType,
+		}
+	}
+
+	if len(cfg.Prompts) > 0 {
+		body.AudioToLLM = true
+		body.AudioToLLMConf = &prerecorderv2.AudioToLLMConf{
+			Prompts: cfg.Prompts,
+		}
+	}
+
+	if err = applyDictionaries(cfg, body); err != nil {
+		return "", "", fmt.Errorf("init transcription: invalid dictionary: %w", err)
+	}
+
+	if body.CustomMetadata, err = CustomMetadata(cfg, fileURL.String()); err != nil {
+		return "", "", fmt.Errorf("init transcription: invalid metadata: %w", err)
+	}
+
+	if cfg.CallbackURL != "" {
+		callbackURL, err := makeCallbackURL(cfg.CallbackURL, cfg.CallbackSecret)
+		if err != nil {
+			return "", "", fmt.Errorf("init transcription: callback url is not valid: %w", err)
+		}
+		body.Callback = true
+		body.CallbackConf = &prerecorderv2.CallbackConf{URL: callbackURL, Method: "POST"}
+	}
+
+	if cfg.Extract != "" {
+		classes, err := extraction.LoadClasses(cfg.Extract)
+		if err != nil {
+			return "", "", fmt.Errorf("init transcription: invalid extraction classes: %w", err)
+		}
+		body.StructuredData = true
+		body.ExtractionConf = &prerecorderv2.StructuredDataConf{Classes: classes}
 	}
 
 	resp, err := uc.httpClient.InitTranscription(body)
 	if err != nil {
-		uc.l.Print("Failed init transcription: ", err)
-		return "", "", err
+		return "", "", fmt.Errorf("failed init transcription: %w", err)
 	}
 
 	return resp.ResultUrl, resp.ID, err
 }
 
-func (uc *AudoUploader) PollingResult(ctx context.Context, taskID string, timeInterval time.Duration, timeout time.Duration) (*prerecorderv2.Result, error) {
+// Метаданные задачи: автоматические теги (исходный файл, хост, пакет) и теги из флагов --meta.
+// Теги из флагов имеют приоритет. В режиме callback добавляется имя файла результата для serve-callbacks
+func CustomMetadata(cfg config.Config, rawURL string) (map[string]any, error) {
+	tags, err := repo.ParseTags(cfg.Meta)
+	if err != nil {
+		return nil, err
+	}
+	audioURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := map[string]any{}
+
+	// у загруженного в Gladia файла в url только uuid, имя исходного файла известно только из --source
+	source := cfg.AudioFile
+	if source == "" && !isUploadURL(cfg, audioURL) {
+		source = path.Base(audioURL.Path)
+	}
+	if source != "" && source != "." && source != "/" {
+		meta["source_file"] = filepath.Base(source)
+	}
+
+	if host, err := os.Hostname(); err == nil {
+		meta["host"] = host
+	}
+	if cfg.BatchID != "" {
+		meta["batch_id"] = cfg.BatchID
+	}
+
+	for key, value := range tags {
+		meta[key] = value
+	}
+	if cfg.CallbackURL != "" {
+		meta["output_file"] = filepath.Base(cfg.OutputFile)
+	}
+	return meta, nil
+}
+
+// Файл, загруженный командой upload: https://api.gladia.io/file/<uuid>
+func isUploadURL(cfg config.Config, audioURL *url.URL) bool {
+	base, err := url.Parse(cfg.BaseUrl)
+	if err != nil || base.Host == "" {
+		return false
+	}
+	return strings.EqualFold(audioURL.Host, base.Host) && strings.HasPrefix(audioURL.Path, "/file/")
+}
+
+// Добавить секрет в параметры callback url, сервер serve-callbacks проверяет его при получении результата
+func makeCallbackURL(rawURL string, secret string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("%s: unsupported callback url scheme", rawURL)
+	}
+
+	if secret != "" {
+		query := u.Query()
+		query.Set("secret", secret)
+		u.RawQuery = query.Encode()
+	}
+	return u.String(), nil
+}
+
+// Добавить в запрос словари терминов и написаний из профиля и флагов
+func applyDictionaries(cfg config.Config, body *prerecorderv2.PreRecorderBody) error {
+	terms, err := dictionary.LoadVocabularies(cfg.VocabularyFile, cfg.Vocabulary)
+	if err != nil {
+		return err
+	}
+	if len(terms) > 0 {
+		items := make([]prerecorderv2.VocabularyItem, 0, len(terms))
+		for _, term := range terms {
+			items = append(items, prerecorderv2.VocabularyItem{Value: term.Value, Intensity: term.Intensity})
+		}
+		body.CustomVocabulary = true
+		body.VocabularyConf = &prerecorderv2.CustomVocabularyConf{Vocabulary: items}
+	}
+
+	if !cfg.ServerSpelling {
+		// словарь написаний будет применен локально к результату
+		return nil
+	}
+
+	spelling, err := dictionary.LoadSpellings(cfg.SpellingFile, cfg.Spelling)
+	if err != nil {
+		return err
+	}
+	if len(spelling) > 0 {
+		body.CustomSpelling = true
+		body.SpellingConf = &prerecorderv2.CustomSpellingConf{SpellingDictionary: spelling}
+	}
+	return nil
+}
+
+func (uc *AudoUploader) PollingResult(ctx context.Context, taskID string, timeInterval time.Duration, timeout time.Duration) (*prerecorderv2.PreRecorderResultResponse, error) {
 
 	ticker := time.NewTicker(timeInterval)
 	defer ticker.Stop()
@@ -116,6 +260,12 @@ func (uc *AudoUploader) PollingResult(ctx context.Context, taskID string, timeIn
 	var resp *prerecorderv2.PreRecorderResultResponse
 	var err error
 
+	// нулевой таймаут - ждать без ограничения
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		deadline = time.After(timeout)
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -127,25 +277,53 @@ func (uc *AudoUploader) PollingResult(ctx context.Context, taskID string, timeIn
 			} else if resp.Status == "error" {
 				return nil, fmt.Errorf("error: %s", resp.ErrorCode)
 			} else if resp.Status == "done" {
-				return resp.Result, nil
+				return resp, nil
 			}
-		case <-time.After(timeout):
-			return nil, fmt.Errorf("the result was not obtained within: %d", timeout)
+		case <-deadline:
+			return nil, fmt.Errorf("the result was not obtained within: %s", timeout)
 		}
 	}
 }
 
+// Сохранить результат в файл. Если формат не задан, он определяется по расширению файла
+func (uc *AudoUploader) Dump(resp *prerecorderv2.PreRecorderResultResponse, filePath string, format string, opts output.Options) error {
+	var formatter output.Formatter
+	var err error
+
+	if format != "" {
+		formatter, err = output.Get(format)
+	} else {
+		formatter, err = output.ByExtension(filePath)
+	}
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("%s: failed create output file: %w", filePath, err)
+	}
+	defer file.Close()
+
+	if err = formatter.Format(file, resp, opts); err != nil {
+		return fmt.Errorf("%s: failed write result: %w", filePath, err)
+	}
+
+	uc.log.Info("result saved", "path", filePath, "format", format)
+
+	return nil
+}
+
 func (uc *AudoUploader) Info(taskID string) (*prerecorderv2.Result, error) {
 	resp, err := uc.httpClient.GetTranscriptionResult(taskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed get task result: %w", err)
 	}
 
-	uc.l.Print("Task status: ", resp.Status)
+	uc.log.Info("task status", "id", taskID, "status", resp.Status)
 
 	if resp.Status == "error" {
-		uc.l.Print("errors:")
-		uc.l.Print("\terror code:", resp.ErrorCode)
+		uc.log.Warn("task failed", "id", taskID, "error_code", resp.ErrorCode)
 	} else if resp.Status == "done" {
 		return resp.Result, nil
 	}
@@ -153,37 +331,50 @@ func (uc *AudoUploader) Info(taskID string) (*prerecorderv2.Result, error) {
 	return nil, nil
 }
 
-func (uc *AudoUploader) List(limit int) (string, error) {
-	resp, err := uc.httpClient.List(limit)
-	if err != nil {
-		return "", fmt.Errorf("failed get tasks listt: %w", err)
-	}
-
-	type result struct {
-		id              int
-		uid             string
-		status          string
-		transactionType string
-		fileName        string
-		Duration        string
-		Date            string
-	}
-
-	var results []result
-
-	for idx, item := range resp.Items {
-		res := result{
-			id:              idx,
-			uid:             item.ID,
-			status:          item.Status,
-			transactionType: "ХЗ ГДЕ БРАТЬ",
-			fileName:        item.File.Filename,
-			Duration:        "Посчитать разницу: completed_at - created_at",
-			Date:            item.CompletedAT,
-		}
+// Список задач на сервере, подходящих под фильтр по статусу, метаданным и дате создания.
+// Фильтрация выполняется на клиенте, поэтому страницы запрашиваются, пока не наберется limit задач,
+// не встретится задача старше q.Since или список не закончится. С q.Since и без limit возвращаются все задачи периода
+func (uc *AudoUploader) List(q repo.Query) ([]prerecorderv2.ListItem, error) {
+	limit := q.Limit
+	if limit <= 0 && q.Since.IsZero() {
+		limit = defaultListLimit
+	}
 
-		results = append(results, res)
+	pageSize := maxListLimit
+	if q.Status == "" && len(q.Where) == 0 && q.Since.IsZero() {
+		pageSize = min(limit, maxListLimit)
 	}
 
-	return "", nil
+	var items []prerecorderv2.ListItem
+	err := uc.pages(pageSize, func(page []prerecorderv2.ListItem) bool {
+		for _, item := range page {
+			// задачи идут от новых к старым, дальше только более старые
+			if !q.MatchCreated(item.CreatedAT) {
+				return false
+			}
+			if !q.Match(item.Status, item.CustomMetadata) {
+				continue
+			}
+			items = append(items, item)
+			if len(items) == limit {
+				return false
+			}
+		}
+		return true
+	})
+	return items, err
+}
+
+// Обойти задачи на сервере страницами от новых к старым, пока visit возвращает true и есть следующая страница
+func (uc *AudoUploader) pages(pageSize int, visit func(page []prerecorderv2.ListItem) bool) error {
+	for offset := 0; ; {
+		resp, err := uc.httpClient.List(offset, pageSize)
+		if err != nil {
+			return fmt.Errorf("failed get tasks list: %w", err)
+		}
+		if !visit(resp.Items) || len(resp.Items) < pageSize {
+			return nil
+		}
+		offset += len(resp.Items)
+	}
 }
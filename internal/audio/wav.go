@@ -0,0 +1,258 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Коды формата WAVE
+const (
+	wavFormatPCM        = 0x0001
+	wavFormatFloat      = 0x0003
+	wavFormatExtensible = 0xFFFE
+)
+
+// Заголовок fmt WAV файла
+type WAVFormat struct {
+	Format        uint16
+	Channels      uint16
+	SampleRate    uint32
+	BitsPerSample uint16
+	BlockAlign    uint16
+}
+
+var errNotWAV = errors.New("not a RIFF/WAVE file")
+
+/*
+# Разделить многоканальный WAV на моно файлы
+
+Поддерживаются PCM 8/16/24/32 бит и float 32/64 бит, в том числе
+WAVE_FORMAT_EXTENSIBLE. Файлы каналов пишутся в dir как name.ch0.wav,
+name.ch1.wav и т.д., формат сэмплов не меняется. Данные читаются потоком,
+поэтому длинные записи не загружаются в память целиком. При ошибке
+созданные файлы каналов удаляются.
+*/
+func SplitWAV(path string, dir string) (paths []string, err error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed open audio: %w", path, err)
+	}
+	defer in.Close()
+
+	format, dataSize, err := readWAVHeader(in)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if format.Channels < 2 {
+		return nil, fmt.Errorf("%s: audio has %d channel, nothing to split", path, format.Channels)
+	}
+	dataSize = wavDataSize(in, dataSize)
+	frames := dataSize / uint32(format.BlockAlign)
+	if frames == 0 {
+		return nil, fmt.Errorf("%s: no audio data in WAV file", path)
+	}
+
+	if dir == "" {
+		dir = filepath.Dir(path)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("%s: failed create directory: %w", dir, err)
+	}
+
+	sampleSize := int(format.BitsPerSample / 8)
+	mono := format
+	mono.Channels = 1
+	mono.BlockAlign = uint16(sampleSize)
+
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	created := make([]string, 0, format.Channels)
+	files := make([]*os.File, format.Channels)
+	writers := make([]*bufio.Writer, format.Channels)
+	defer func() {
+		for _, f := range files {
+			if f != nil {
+				f.Close()
+			}
+		}
+		// недописанные файлы каналов не должны попасть в загрузку
+		if err != nil {
+			for _, p := range created {
+				os.Remove(p)
+			}
+		}
+	}()
+
+	for ch := range files {
+		channelPath := filepath.Join(dir, fmt.Sprintf("%s.ch%d.wav", base, ch))
+		if files[ch], err = os.Create(channelPath); err != nil {
+			return nil, fmt.Errorf("%s: failed create channel file: %w", channelPath, err)
+		}
+		created = append(created, channelPath)
+		writers[ch] = bufio.NewWriter(files[ch])
+		if err := writeWAVHeader(writers[ch], mono, frames*uint32(sampleSize)); err != nil {
+			return nil, fmt.Errorf("%s: %w", channelPath, err)
+		}
+	}
+
+	reader := bufio.NewReaderSize(io.LimitReader(in, int64(frames)*int64(format.BlockAlign)), 64*1024)
+	frame := make([]byte, format.BlockAlign)
+	for i := uint32(0); i < frames; i++ {
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			return nil, fmt.Errorf("%s: truncated audio data: %w", path, err)
+		}
+		for ch, w := range writers {
+			w.Write(frame[ch*sampleSize : (ch+1)*sampleSize])
+		}
+	}
+
+	for ch, w := range writers {
+		// чанки RIFF выравниваются по четному размеру
+		if frames*uint32(sampleSize)%2 == 1 {
+			w.WriteByte(0)
+		}
+		if err := w.Flush(); err != nil {
+			return nil, fmt.Errorf("%s: failed write channel file: %w", created[ch], err)
+		}
+		if err := files[ch].Close(); err != nil {
+			return nil, fmt.Errorf("%s: failed write channel file: %w", created[ch], err)
+		}
+		files[ch] = nil
+	}
+	return created, nil
+}
+
+// У потоковых WAV размер данных не заполнен (0 или 0xFFFFFFFF), тогда данные идут до конца файла.
+// f должен стоять на начале данных
+func wavDataSize(f *os.File, dataSize uint32) uint32 {
+	if dataSize != 0 && dataSize != 0xFFFFFFFF {
+		return dataSize
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return dataSize
+	}
+	pos, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return dataSize
+	}
+	return uint32(min(max(info.Size()-pos, 0), 0xFFFFFFFF))
+}
+
+// Количество каналов WAV файла, для остальных форматов ошибка
+func WAVChannels(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	format, _, err := readWAVHeader(f)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	return int(format.Channels), nil
+}
+
+// Прочитать чанки до начала данных, r остается на первом сэмпле
+func readWAVHeader(r io.Reader) (WAVFormat, uint32, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return WAVFormat{}, 0, errNotWAV
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return WAVFormat{}, 0, errNotWAV
+	}
+
+	var format WAVFormat
+	hasFormat := false
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return WAVFormat{}, 0, errors.New("no data chunk in WAV file")
+		}
+		id, size := string(chunk[0:4]), binary.LittleEndian.Uint32(chunk[4:8])
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return WAVFormat{}, 0, errors.New("bad fmt chunk in WAV file")
+			}
+			body := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return WAVFormat{}, 0, errors.New("bad fmt chunk in WAV file")
+			}
+			format = WAVFormat{
+				Format:        binary.LittleEndian.Uint16(body[0:2]),
+				Channels:      binary.LittleEndian.Uint16(body[2:4]),
+				SampleRate:    binary.LittleEndian.Uint32(body[4:8]),
+				BlockAlign:    binary.LittleEndian.Uint16(body[12:14]),
+				BitsPerSample: binary.LittleEndian.Uint16(body[14:16]),
+			}
+			// в extensible формате настоящий код формата - первые байты SubFormat GUID
+			if format.Format == wavFormatExtensible && size >= 26 {
+				format.Format = binary.LittleEndian.Uint16(body[24:26])
+			}
+			hasFormat = true
+
+		case "data":
+			if !hasFormat {
+				return WAVFormat{}, 0, errors.New("data chunk before fmt chunk in WAV file")
+			}
+			if err := validateWAVFormat(format); err != nil {
+				return WAVFormat{}, 0, err
+			}
+			return format, size, nil
+
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(size+size%2)); err != nil {
+				return WAVFormat{}, 0, errors.New("no data chunk in WAV file")
+			}
+		}
+	}
+}
+
+func validateWAVFormat(f WAVFormat) error {
+	switch {
+	case f.Format != wavFormatPCM && f.Format != wavFormatFloat:
+		return fmt.Errorf("unsupported WAV encoding 0x%04x, only PCM and float are supported", f.Format)
+	case f.BitsPerSample == 0 || f.BitsPerSample%8 != 0:
+		return fmt.Errorf("unsupported WAV sample size %d bits", f.BitsPerSample)
+	case f.Channels == 0 || int(f.BlockAlign) != int(f.Channels)*int(f.BitsPerSample/8):
+		return errors.New("bad block align in WAV file")
+	}
+	return nil
+}
+
+// Минимальный заголовок: 44 байта для PCM, для float добавляется пустое расширение fmt, как требует спецификация
+func writeWAVHeader(w io.Writer, f WAVFormat, dataSize uint32) error {
+	fmtSize := uint32(16)
+	if f.Format == wavFormatFloat {
+		fmtSize = 18
+	}
+
+	var b []byte
+	b = append(b, "RIFF"...)
+	b = binary.LittleEndian.AppendUint32(b, 4+(8+fmtSize)+(8+dataSize+dataSize%2))
+	b = append(b, "WAVEfmt "...)
+	b = binary.LittleEndian.AppendUint32(b, fmtSize)
+	b = binary.LittleEndian.AppendUint16(b, f.Format)
+	b = binary.LittleEndian.AppendUint16(b, f.Channels)
+	b = binary.LittleEndian.AppendUint32(b, f.SampleRate)
+	b = binary.LittleEndian.AppendUint32(b, f.SampleRate*uint32(f.BlockAlign))
+	b = binary.LittleEndian.AppendUint16(b, f.BlockAlign)
+	b = binary.LittleEndian.AppendUint16(b, f.BitsPerSample)
+	if fmtSize == 18 {
+		b = binary.LittleEndian.AppendUint16(b, 0)
+	}
+	b = append(b, "data"...)
+	b = binary.LittleEndian.AppendUint32(b, dataSize)
+
+	_, err := w.Write(b)
+	return err
+}
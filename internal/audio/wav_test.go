@@ -0,0 +1,129 @@
+package audio
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Стерео PCM16: левый канал 1, 2, 3, правый -1, -2, -3
+var stereoSamples = [][]int16{{1, 2, 3}, {-1, -2, -3}}
+
+func writeStereoWAV(t *testing.T, dataSize uint32) string {
+	t.Helper()
+	var data []byte
+	for i := range stereoSamples[0] {
+		for ch := range stereoSamples {
+			data = binary.LittleEndian.AppendUint16(data, uint16(stereoSamples[ch][i]))
+		}
+	}
+	if dataSize == 0 {
+		dataSize = uint32(len(data))
+	}
+
+	path := filepath.Join(t.TempDir(), "call.wav")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	format := WAVFormat{Format: wavFormatPCM, Channels: 2, SampleRate: 8000, BitsPerSample: 16, BlockAlign: 4}
+	if err := writeWAVHeader(f, format, dataSize); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readMonoSamples(t *testing.T, path string) []int16 {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	format, dataSize, err := readWAVHeader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format.Channels != 1 || format.BlockAlign != 2 || format.SampleRate != 8000 {
+		t.Fatalf("%s: unexpected format %+v", path, format)
+	}
+	samples := make([]int16, dataSize/2)
+	if err := binary.Read(f, binary.LittleEndian, samples); err != nil {
+		t.Fatal(err)
+	}
+	return samples
+}
+
+func TestSplitWAV(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		dataSize uint32
+	}{
+		{"data size in header", 0},
+		{"streaming size 0xFFFFFFFF", 0xFFFFFFFF},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := writeStereoWAV(t, tc.dataSize)
+
+			paths, err := SplitWAV(path, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(paths) != 2 {
+				t.Fatalf("got %d channel files, want 2", len(paths))
+			}
+			for ch, p := range paths {
+				if got := readMonoSamples(t, p); !slices.Equal(got, stereoSamples[ch]) {
+					t.Errorf("channel %d: samples %v, want %v", ch, got, stereoSamples[ch])
+				}
+			}
+		})
+	}
+}
+
+// Потоковый WAV с нулевым размером данных делится по размеру файла
+func TestSplitWAVZeroDataSize(t *testing.T) {
+	path := writeStereoWAV(t, 0)
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// размер чанка data - последние 4 байта 44-байтного заголовка
+	if _, err := f.WriteAt([]byte{0, 0, 0, 0}, 40); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	paths, err := SplitWAV(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ch, p := range paths {
+		if got := readMonoSamples(t, p); !slices.Equal(got, stereoSamples[ch]) {
+			t.Errorf("channel %d: samples %v, want %v", ch, got, stereoSamples[ch])
+		}
+	}
+}
+
+func TestSplitWAVTruncatedRemovesChannels(t *testing.T) {
+	path := writeStereoWAV(t, 100)
+	dir := t.TempDir()
+
+	if _, err := SplitWAV(path, dir); err == nil {
+		t.Fatal("expected truncated audio data error")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("partial channel files left after error: %v", entries)
+	}
+}
@@ -0,0 +1,63 @@
+package billing
+
+import (
+	"sort"
+
+	"go-gladia.io-client/internal/config"
+)
+
+// Предварительная оценка стоимости транскрибации
+type Estimate struct {
+	Duration float64 // секунды аудио
+	Channels int
+	Features []string
+	Hours    float64 // ожидаемые оплачиваемые часы
+	Cost     float64
+}
+
+/*
+# Оценить стоимость до загрузки
+
+Каждый канал транскрибируется отдельно, поэтому оплачиваемое время -
+длительность, умноженная на количество каналов. Функции берутся из
+конфигурации так же, как при создании задачи, плюс extra.
+*/
+func EstimateCost(duration float64, channels int, cfg config.Config, extra []string, prices Prices) Estimate {
+	channels = max(channels, 1)
+	e := Estimate{
+		Duration: duration,
+		Channels: channels,
+		Features: RequestFeatures(cfg, extra),
+		Hours:    duration * float64(channels) / 3600,
+	}
+	e.Cost = prices.Cost(e.Hours, e.Features)
+	return e
+}
+
+// Платные функции, которые будут включены в запросе на транскрибацию, по именам параметров API
+func RequestFeatures(cfg config.Config, extra []string) []string {
+	enabled := map[string]bool{
+		"diarization":                cfg.Diarization,
+		"translation":                cfg.Translation || len(cfg.TargetLanguages) > 0,
+		"sentiment_analysis":         true, // всегда включен в запросе
+		"summarization":              cfg.Summarization,
+		"chapterization":             cfg.Chapterization,
+		"named_entity_recognition":   cfg.NamedEntities || len(cfg.Redact) > 0,
+		"moderation":                 cfg.Moderation,
+		"audio_to_llm":               len(cfg.Prompts) > 0,
+		"structured_data_extraction": cfg.Extract != "",
+		"custom_spelling":            cfg.ServerSpelling && (cfg.SpellingFile != "" || cfg.Spelling != ""),
+	}
+	for _, feature := range extra {
+		enabled[feature] = true
+	}
+
+	var features []string
+	for name, on := range enabled {
+		if on && name != "" {
+			features = append(features, name)
+		}
+	}
+	sort.Strings(features)
+	return features
+}
@@ -0,0 +1,112 @@
+package billing
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Цены за час обработанного аудио
+type Prices struct {
+	PerHour  float64            // базовая транскрибация
+	AddOns   map[string]float64 // надбавки за включенные функции, по имени параметра запроса
+	Currency string
+}
+
+// Разобрать надбавки вида diarization=0.1,translation=0.2
+func ParseAddOns(values []string) (map[string]float64, error) {
+	addOns := map[string]float64{}
+	for _, value := range values {
+		for _, pair := range strings.Split(value, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+			name, price, ok := strings.Cut(pair, "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if !ok || name == "" {
+				return nil, fmt.Errorf("invalid add-on price %q, expected feature=price", pair)
+			}
+			p, err := strconv.ParseFloat(strings.TrimSpace(price), 64)
+			if err != nil || p < 0 {
+				return nil, fmt.Errorf("invalid add-on price %q: expected a non-negative number", pair)
+			}
+			addOns[name] = p
+		}
+	}
+	return addOns, nil
+}
+
+// Цена часа с учетом надбавок за функции
+func (p Prices) HourRate(features []string) float64 {
+	rate := p.PerHour
+	for _, feature := range features {
+		rate += p.AddOns[feature]
+	}
+	return rate
+}
+
+// Цены не заданы: любая оценка стоимости равна нулю
+func (p Prices) IsZero() bool {
+	for _, price := range p.AddOns {
+		if price > 0 {
+			return false
+		}
+	}
+	return p.PerHour == 0
+}
+
+// Стоимость hours часов аудио с функциями features
+func (p Prices) Cost(hours float64, features []string) float64 {
+	return hours * p.HourRate(features)
+}
+
+// Сумма с валютой
+func (p Prices) Format(amount float64) string {
+	if p.Currency == "" {
+		return fmt.Sprintf("%.2f", amount)
+	}
+	return fmt.Sprintf("%.2f %s", amount, p.Currency)
+}
+
+// Платные функции, включенные в параметрах запроса, по именам параметров API
+func Features(p *prerecorderv2.ReqParams) []string {
+	if p == nil {
+		return nil
+	}
+	enabled := map[string]bool{
+		"diarization":                p.Diarization,
+		"diarization_enhanced":       p.DiarizationEnhanced,
+		"translation":                p.Translation,
+		"summarization":              p.Summarization,
+		"chapterization":             p.Chapterization,
+		"moderation":                 p.Moderation,
+		"named_entity_recognition":   p.NamedEntityRecognition,
+		"sentiment_analysis":         p.SentimentAnalysis,
+		"audio_to_llm":               p.AudioToLLM,
+		"structured_data_extraction": p.StructuredDataExtraction,
+		"audio_enhancer":             p.AudioEnhancer,
+		"name_consistency":           p.NameConsistency,
+		"speaker_reidentification":   p.SpeakerReidentification,
+		"custom_spelling":            p.CustomSpelling,
+		"subtitles":                  p.Subtitles,
+	}
+	var features []string
+	for name, on := range enabled {
+		if on {
+			features = append(features, name)
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
+// Профиль задачи - набор включенных платных функций, transcription без функций
+func Profile(features []string) string {
+	if len(features) == 0 {
+		return "transcription"
+	}
+	return strings.Join(features, "+")
+}
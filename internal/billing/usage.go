@@ -0,0 +1,300 @@
+package billing
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Оплаченная задача
+type Job struct {
+	ID                string
+	Source            string // local или remote
+	Status            string
+	Created           time.Time
+	File              string
+	AudioDuration     float64 // секунды
+	Channels          int
+	BillingTime       float64 // секунды
+	TranscriptionTime float64 // секунды
+	Features          []string
+	Tags              map[string]any
+}
+
+// Оплаченные часы
+func (j Job) Hours() float64 {
+	return j.BillingTime / 3600
+}
+
+// Задача в очереди или в обработке, оплаченного времени еще нет
+func (j Job) Pending() bool {
+	return j.Status == "queued" || j.Status == "processing"
+}
+
+// Ожидаемые часы задачи в очереди: длительность по всем каналам, как в EstimateCost
+func (j Job) EstimatedHours() float64 {
+	return j.AudioDuration * float64(max(j.Channels, 1)) / 3600
+}
+
+// Задача из локальной истории
+func FromResult(resp *prerecorderv2.PreRecorderResultResponse) Job {
+	job := Job{ID: resp.ID, Source: "local", Status: resp.Status, Created: parseTime(resp.CreatedAt), Features: Features(resp.RequestParams)}
+	job.Tags, _ = resp.CustomMeta.(map[string]any)
+	if resp.File != nil {
+		job.File, job.AudioDuration, job.Channels = resp.File.Filename, resp.File.AudioDuration, resp.File.NumberOfChannels
+	}
+	if resp.Result != nil {
+		job.BillingTime = resp.Result.Metadata.BillingTime
+		job.TranscriptionTime = resp.Result.Metadata.TranscriptionTime
+		job.AudioDuration = max(job.AudioDuration, resp.Result.Metadata.AudioDuration)
+	}
+	return job
+}
+
+// Задача из списка на сервере
+func FromListItem(item prerecorderv2.ListItem) Job {
+	params := item.ReqParams
+	return Job{
+		ID:                item.ID,
+		Source:            "remote",
+		Status:            item.Status,
+		Created:           parseTime(item.CreatedAT),
+		File:              item.File.Filename,
+		AudioDuration:     max(item.File.AudioDuration, item.Result.Metadata.AudioDuration),
+		Channels:          item.File.NumberOfChannels,
+		BillingTime:       item.Result.Metadata.BillingTime,
+		TranscriptionTime: item.Result.Metadata.TranscriptionTime,
+		Features:          Features(&params),
+		Tags:              item.CustomMetadata,
+	}
+}
+
+func parseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
+
+// Объединить задачи по id, из нескольких копий остается первая
+func Dedup(jobs []Job) []Job {
+	seen := map[string]bool{}
+	out := jobs[:0:0]
+	for _, job := range jobs {
+		if job.ID != "" && seen[job.ID] {
+			continue
+		}
+		seen[job.ID] = true
+		out = append(out, job)
+	}
+	return out
+}
+
+// Строка сводки по группе задач
+type Row struct {
+	Key               string
+	Jobs              int
+	AudioDuration     float64
+	BillingTime       float64
+	TranscriptionTime float64
+	Cost              float64
+}
+
+func (r Row) Hours() float64 {
+	return r.BillingTime / 3600
+}
+
+// Ключи группировки
+const (
+	ByDay     = "day"
+	ByWeek    = "week"
+	ByMonth   = "month"
+	ByProfile = "profile"
+	ByTag     = "tag:" // tag:<ключ метаданных>
+)
+
+/*
+# Ключ группировки задачи
+
+day - 2006-01-02, week - ISO неделя 2006-W01, month - 2006-01, profile -
+набор платных функций, tag:KEY - значение тега KEY пользовательских
+метаданных. Задачи без даты или тега попадают в группу "-".
+*/
+func GroupKey(job Job, by string) (string, error) {
+	if key, ok := strings.CutPrefix(by, ByTag); ok {
+		if value, found := job.Tags[key]; found && key != "" {
+			return fmt.Sprint(value), nil
+		} else if key == "" {
+			return "", fmt.Errorf("invalid grouping %q, expected tag:KEY", by)
+		}
+		return "-", nil
+	}
+	if by == ByProfile {
+		return Profile(job.Features), nil
+	}
+
+	if job.Created.IsZero() && (by == ByDay || by == ByWeek || by == ByMonth) {
+		return "-", nil
+	}
+	switch by {
+	case ByDay:
+		return job.Created.Format("2006-01-02"), nil
+	case ByWeek:
+		year, week := job.Created.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week), nil
+	case ByMonth:
+		return job.Created.Format("2006-01"), nil
+	}
+	return "", fmt.Errorf("unknown grouping %q, available: day, week, month, profile, tag:KEY", by)
+}
+
+// Сводка по группам, отсортированная по ключу
+func Group(jobs []Job, by string, prices Prices) ([]Row, error) {
+	byKey := map[string]*Row{}
+	for _, job := range jobs {
+		key, err := GroupKey(job, by)
+		if err != nil {
+			return nil, err
+		}
+		row, ok := byKey[key]
+		if !ok {
+			row = &Row{Key: key}
+			byKey[key] = row
+		}
+		row.Jobs++
+		row.AudioDuration += job.AudioDuration
+		row.BillingTime += job.BillingTime
+		row.TranscriptionTime += job.TranscriptionTime
+		row.Cost += prices.Cost(job.Hours(), job.Features)
+	}
+
+	rows := make([]Row, 0, len(byKey))
+	for _, row := range byKey {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Key < rows[j].Key })
+	return rows, nil
+}
+
+// Итог по всем строкам
+func Total(rows []Row) Row {
+	total := Row{Key: "TOTAL"}
+	for _, row := range rows {
+		total.Jobs += row.Jobs
+		total.AudioDuration += row.AudioDuration
+		total.BillingTime += row.BillingTime
+		total.TranscriptionTime += row.TranscriptionTime
+		total.Cost += row.Cost
+	}
+	return total
+}
+
+// Бюджет за месяц
+type Budget struct {
+	Month string // 2006-01
+	Spent float64
+	Limit float64
+}
+
+func (b Budget) Exceeded() bool {
+	return b.Spent >= b.Limit
+}
+
+/*
+# Проверка месячного бюджета
+
+Для каждого месяца, в котором есть задачи, расходы сравниваются с limit.
+Месяц попадает в результат, если потрачено не меньше доли warnAt от
+бюджета (0.8 - предупреждать с 80%). limit <= 0 - бюджет не задан.
+*/
+func CheckBudget(jobs []Job, prices Prices, limit, warnAt float64) []Budget {
+	if limit <= 0 {
+		return nil
+	}
+	rows, _ := Group(jobs, ByMonth, prices)
+
+	var budgets []Budget
+	for _, row := range rows {
+		if row.Key != "-" && row.Cost >= limit*warnAt {
+			budgets = append(budgets, Budget{Month: row.Key, Spent: row.Cost, Limit: limit})
+		}
+	}
+	return budgets
+}
+
+// Расходы за день day (UTC): завершенные задачи по оплаченному времени, задачи в очереди и в обработке - по оценке
+func DaySpend(jobs []Job, prices Prices, day time.Time) float64 {
+	key := day.UTC().Format("2006-01-02")
+	spent := 0.0
+	for _, job := range jobs {
+		if job.Created.IsZero() || job.Created.Format("2006-01-02") != key {
+			continue
+		}
+		hours := job.Hours()
+		if job.Pending() {
+			hours = job.EstimatedHours()
+		}
+		spent += prices.Cost(hours, job.Features)
+	}
+	return spent
+}
+
+var csvHeader = []string{
+	"id", "source", "date", "file", "audio_duration", "billing_time", "billed_hours",
+	"transcription_time", "profile", "hour_rate", "cost", "currency", "tags",
+}
+
+// Выгрузка задач в csv для бухгалтерии, строка на задачу
+func WriteCSV(w io.Writer, jobs []Job, prices Prices) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		date := ""
+		if !job.Created.IsZero() {
+			date = job.Created.Format(time.RFC3339)
+		}
+		err := cw.Write([]string{
+			job.ID,
+			job.Source,
+			date,
+			job.File,
+			formatFloat(job.AudioDuration),
+			formatFloat(job.BillingTime),
+			strconv.FormatFloat(job.Hours(), 'f', 4, 64),
+			formatFloat(job.TranscriptionTime),
+			Profile(job.Features),
+			strconv.FormatFloat(prices.HourRate(job.Features), 'f', 4, 64),
+			strconv.FormatFloat(prices.Cost(job.Hours(), job.Features), 'f', 4, 64),
+			prices.Currency,
+			formatTags(job.Tags),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+func formatTags(tags map[string]any) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ";")
+}
@@ -0,0 +1,158 @@
+package callback
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+	"go-gladia.io-client/internal/repo"
+	"go-gladia.io-client/pkg/logger"
+)
+
+// Ограничение на размер тела callback, результат длинной записи может весить десятки мегабайт
+const maxPayloadSize = 64 << 20
+
+// Обработчик полученного результата, например сохранение в файлы выбранных форматов
+type ResultHandler func(resp *prerecorderv2.PreRecorderResultResponse) error
+
+// HTTP сервер, принимающий callback от Gladia вместо опроса GET /v2/pre-recorded/{id}
+type Server struct {
+	log      logger.ILogger
+	secret   string
+	history  *repo.FilesRepo
+	onResult ResultHandler
+}
+
+// Пустой secret отключает проверку
+func NewServer(log logger.ILogger, secret string, history *repo.FilesRepo, onResult ResultHandler) *Server {
+	return &Server{
+		log:      log,
+		secret:   secret,
+		history:  history,
+		onResult: onResult,
+	}
+}
+
+// Запустить сервер и остановить его при отмене ctx
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.log.Info("listen callbacks", "addr", addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.authorized(r) {
+		s.log.Warn("callback rejected: invalid secret", "remote_addr", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var payload prerecorderv2.CallbackPayload
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxPayloadSize)).Decode(&payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if payload.ID == "" || strings.ContainsAny(payload.ID, `/\`) {
+		http.Error(w, "invalid task id", http.StatusBadRequest)
+		return
+	}
+	if err := payload.Payload.PartialError(); err != nil {
+		s.log.Warn("incomplete callback result", "id", payload.ID, "error", err)
+	}
+
+	resp, err := s.handle(&payload)
+	if err != nil {
+		s.log.Error("failed handle callback", "id", payload.ID, "error", err)
+		http.Error(w, "failed handle callback", http.StatusInternalServerError)
+		return
+	}
+
+	s.log.Info("callback received", "id", resp.ID, "status", resp.Status)
+	w.WriteHeader(http.StatusOK)
+}
+
+// Секрет передается в параметре secret callback url или в заголовке X-Callback-Secret
+func (s *Server) authorized(r *http.Request) bool {
+	if s.secret == "" {
+		return true
+	}
+
+	got := r.URL.Query().Get("secret")
+	if got == "" {
+		got = r.Header.Get("X-Callback-Secret")
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(s.secret)) == 1
+}
+
+// Сохранить результат в историю, дополнив запись, созданную командой start
+func (s *Server) handle(payload *prerecorderv2.CallbackPayload) (*prerecorderv2.PreRecorderResultResponse, error) {
+	resp, err := s.history.Get(payload.ID)
+	if errors.Is(err, repo.ErrNotFound) {
+		resp = &prerecorderv2.PreRecorderResultResponse{
+			ID:        payload.ID,
+			CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	completedAt := time.Now().UTC().Format(time.RFC3339)
+	resp.CompletedAt = &completedAt
+	if payload.CustomMetadata != nil {
+		resp.CustomMeta = payload.CustomMetadata
+	}
+
+	switch payload.Event {
+	case prerecorderv2.CallbackEventSuccess:
+		resp.Status = "done"
+		resp.Result = payload.Payload
+	case prerecorderv2.CallbackEventError:
+		resp.Status = "error"
+		if payload.Error != nil {
+			resp.ErrorCode = payload.Error.StatusCode
+		}
+	default:
+		return nil, fmt.Errorf("unknown callback event %q", payload.Event)
+	}
+
+	// обработчик может изменить результат (например, скрыть персональные данные) до сохранения в историю
+	if resp.Status == "done" && s.onResult != nil {
+		if err := s.onResult(resp); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.history.Save(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,124 @@
+package callback
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+	"go-gladia.io-client/internal/repo"
+	"go-gladia.io-client/pkg/logger"
+)
+
+const testSecret = "s3cret"
+
+// Локальная замена Gladia: отправляет callback на тестовый сервер
+func post(t *testing.T, url string, payload prerecorderv2.CallbackPayload) int {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+func newTestServer(t *testing.T, onResult ResultHandler) (*httptest.Server, *repo.FilesRepo) {
+	t.Helper()
+	history, err := repo.NewFilesRepo(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(NewServer(logger.NewLogger(logger.ERROR), testSecret, history, onResult))
+	t.Cleanup(srv.Close)
+	return srv, history
+}
+
+func successPayload(id string) prerecorderv2.CallbackPayload {
+	result := &prerecorderv2.Result{}
+	result.Transcription.FullTranscript = "hello world"
+	return prerecorderv2.CallbackPayload{
+		ID:             id,
+		Event:          prerecorderv2.CallbackEventSuccess,
+		Payload:        result,
+		CustomMetadata: map[string]any{"project": "alpha"},
+	}
+}
+
+func TestCallbackStoresHistory(t *testing.T) {
+	srv, history := newTestServer(t, nil)
+
+	// запись, созданная командой start в режиме callback
+	if err := history.Save(&prerecorderv2.PreRecorderResultResponse{ID: "task-1", Status: "queued", CreatedAt: "2026-01-02T03:04:05Z"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := post(t, srv.URL+"?secret="+testSecret, successPayload("task-1")); code != http.StatusOK {
+		t.Fatalf("status %d, want 200", code)
+	}
+
+	resp, err := history.Get("task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "done" || resp.CompletedAt == nil {
+		t.Errorf("status %q, completed %v: want done with completion time", resp.Status, resp.CompletedAt)
+	}
+	if resp.CreatedAt != "2026-01-02T03:04:05Z" {
+		t.Errorf("created_at %q: the queued record was not kept", resp.CreatedAt)
+	}
+	if resp.Result == nil || resp.Result.Transcription.FullTranscript != "hello world" {
+		t.Errorf("result was not stored: %+v", resp.Result)
+	}
+	if meta, _ := resp.CustomMeta.(map[string]any); meta["project"] != "alpha" {
+		t.Errorf("custom metadata %v, want project=alpha", resp.CustomMeta)
+	}
+}
+
+func TestCallbackRejectsBadSecret(t *testing.T) {
+	srv, history := newTestServer(t, nil)
+
+	for _, url := range []string{srv.URL, srv.URL + "?secret=wrong"} {
+		if code := post(t, url, successPayload("task-2")); code != http.StatusUnauthorized {
+			t.Errorf("%s: status %d, want 401", url, code)
+		}
+	}
+	if _, err := history.Get("task-2"); err == nil {
+		t.Error("rejected callback was stored in history")
+	}
+}
+
+func TestCallbackRunsOutputHook(t *testing.T) {
+	var got []string
+	srv, history := newTestServer(t, func(resp *prerecorderv2.PreRecorderResultResponse) error {
+		got = append(got, resp.ID)
+		resp.Result.Transcription.FullTranscript = "[REDACTED]"
+		return nil
+	})
+
+	if code := post(t, srv.URL+"?secret="+testSecret, successPayload("task-3")); code != http.StatusOK {
+		t.Fatalf("status %d, want 200", code)
+	}
+	errorPayload := prerecorderv2.CallbackPayload{ID: "task-4", Event: prerecorderv2.CallbackEventError}
+	if code := post(t, srv.URL+"?secret="+testSecret, errorPayload); code != http.StatusOK {
+		t.Fatalf("status %d, want 200", code)
+	}
+
+	if len(got) != 1 || got[0] != "task-3" {
+		t.Fatalf("hook called for %v, want only the successful task-3", got)
+	}
+	// изменения обработчика попадают в историю
+	resp, err := history.Get("task-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Result.Transcription.FullTranscript != "[REDACTED]" {
+		t.Errorf("stored transcript %q, want the hook's change", resp.Result.Transcription.FullTranscript)
+	}
+}
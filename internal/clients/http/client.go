@@ -11,28 +11,62 @@ import (
 	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
 	"go-gladia.io-client/internal/clients/http/models/upload"
 	"go-gladia.io-client/internal/config"
-	"go-gladia.io-client/pkg/output"
+	"go-gladia.io-client/pkg/logger"
 )
 
 type GladiaClient struct {
-	l       output.IOutput
+	log     logger.ILogger
 	token   string
 	client  *http.Client
+	limiter *limiter
 	timeout time.Duration
 	baseURL string
 }
 
-func NewGladiaClient(cfg config.HTTPClientConfig, l output.IOutput, apiToken string, urlPath string) (*GladiaClient, error) {
+func NewGladiaClient(cfg config.HTTPClientConfig, log logger.ILogger, apiToken string, urlPath string) (*GladiaClient, error) {
 	gc := &GladiaClient{
-		l:       l,
+		log:     log,
 		baseURL: urlPath,
 		token:   apiToken,
 		client:  &http.Client{Timeout: cfg.Timeout},
+		limiter: newLimiter(cfg.RateLimit, cfg.RateBurst, cfg.MaxInFlight),
 	}
 
 	return gc, nil
 }
 
+// Выполнить запрос с учетом ограничений частоты и количества одновременных запросов
+func (gc *GladiaClient) do(req *http.Request) (*http.Response, error) {
+	waited, err := gc.limiter.wait(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	if waited > time.Millisecond {
+		gc.log.Debug("rate limit wait", "method", req.Method, "path", req.URL.Path, "waited", waited.Round(time.Millisecond))
+	}
+
+	// заголовки запроса в лог не пишутся, ключ API в них
+	gc.log.Debug("http request", "method", req.Method, "url", req.URL.String())
+	start := time.Now()
+
+	resp, err := gc.client.Do(req)
+	if err != nil {
+		gc.limiter.release()
+		gc.log.Error("http request failed", "method", req.Method, "path", req.URL.Path, "error", err)
+		return nil, err
+	}
+	gc.limiter.update(resp)
+	gc.log.Debug("http response", "method", req.Method, "path", req.URL.Path, "status", resp.StatusCode, "duration", time.Since(start).Round(time.Millisecond))
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		gc.log.Warn("rate limit: too many requests", "method", req.Method, "path", req.URL.Path, "retry_after", resp.Header.Get("Retry-After"))
+	}
+
+	// запрос считается завершенным, когда тело ответа закрыто
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: gc.limiter.release}
+	return resp, nil
+}
+
 /*
 # Загрузить аудио файл на платформу для дальнейшего транскрибирования
 
@@ -50,8 +84,6 @@ func (gc *GladiaClient) AudioUploadFromFile(file *os.File) (*upload.UploadRespon
 		return nil, err
 	}
 
-	gc.l.Print("Try do request:", gc.baseURL+path)
-
 	req, err := http.NewRequest("POST", gc.baseURL+path, body)
 	if err != nil {
 		return nil, err
@@ -59,7 +91,7 @@ func (gc *GladiaClient) AudioUploadFromFile(file *os.File) (*upload.UploadRespon
 	req.Header.Add("Content-Type", multipartHeader)
 	req.Header.Add("x-gladia-key", gc.token)
 
-	resp, err := gc.client.Do(req)
+	resp, err := gc.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -70,9 +102,9 @@ func (gc *GladiaClient) AudioUploadFromFile(file *os.File) (*upload.UploadRespon
 
 	jsonResponse, err := json.Marshal(responseBody)
 	if err != nil {
-		gc.l.Print("response parsing error:", err)
+		gc.log.Warn("failed encode response for log", "error", err)
 	}
-	gc.l.Print("Response:", string(jsonResponse))
+	gc.log.Debug("response body", "body", string(jsonResponse))
 
 	err = httpErrorParse(resp, 200)
 	if err != nil {
@@ -99,8 +131,7 @@ func (gc *GladiaClient) InitTranscription(body *prerecorderv2.PreRecorderBody) (
 		return nil, err
 	}
 
-	gc.l.Print("Try do request:", gc.baseURL+path)
-	gc.l.Print("Body:", string(jsonBody))
+	gc.log.Debug("request body", "body", string(jsonBody))
 
 	req, err := http.NewRequest(method, gc.baseURL+path, bytes.NewBuffer(jsonBody))
 	if err != nil {
@@ -109,7 +140,7 @@ func (gc *GladiaClient) InitTranscription(body *prerecorderv2.PreRecorderBody) (
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("x-gladia-key", gc.token)
 
-	resp, err := gc.client.Do(req)
+	resp, err := gc.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -125,9 +156,9 @@ func (gc *GladiaClient) InitTranscription(body *prerecorderv2.PreRecorderBody) (
 
 	jsonResponse, err := json.Marshal(responseBody)
 	if err != nil {
-		gc.l.Print("response parsing error:", err)
+		gc.log.Warn("failed encode response for log", "error", err)
 	}
-	gc.l.Print("Response:", string(jsonResponse))
+	gc.log.Debug("response body", "body", string(jsonResponse))
 
 	return &responseBody, err
 }
@@ -143,15 +174,13 @@ func (gc *GladiaClient) GetTranscriptionResult(jobId string) (*prerecorderv2.Pre
 	path := fmt.Sprintf("/v2/pre-recorded/%s", jobId)
 	method := "GET"
 
-	gc.l.Print("Try do request:", gc.baseURL+path)
-
 	req, err := http.NewRequest(method, gc.baseURL+path, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("x-gladia-key", gc.token)
-	resp, err := gc.client.Do(req)
+	resp, err := gc.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -164,12 +193,15 @@ func (gc *GladiaClient) GetTranscriptionResult(jobId string) (*prerecorderv2.Pre
 
 	var responseBody prerecorderv2.PreRecorderResultResponse
 	err = json.NewDecoder(resp.Body).Decode(&responseBody)
+	if partialErr := responseBody.Result.PartialError(); partialErr != nil {
+		gc.log.Warn("incomplete task result", "id", jobId, "error", partialErr)
+	}
 
 	jsonResponse, err := json.Marshal(responseBody)
 	if err != nil {
-		gc.l.Print("response parsing error:", err)
+		gc.log.Warn("failed encode response for log", "error", err)
 	}
-	gc.l.Print("Response:", string(jsonResponse))
+	gc.log.Debug("response body", "body", string(jsonResponse))
 
 	return &responseBody, err
 }
@@ -185,15 +217,13 @@ func (gc *GladiaClient) DownloadAudioFile(id string) error {
 	path := fmt.Sprintf("/v2/pre-recorded/%s/file", id)
 	URL := gc.baseURL + path
 
-	gc.l.Print("Try do request:", URL)
-
 	req, err := http.NewRequest("GET", URL, nil)
 	if err != nil {
 		return err
 	}
 	req.Header.Add("x-gladia-key", gc.token)
 
-	resp, err := gc.client.Do(req)
+	resp, err := gc.do(req)
 	if err != nil {
 		return err
 	}
@@ -212,9 +242,9 @@ func (gc *GladiaClient) DownloadAudioFile(id string) error {
 
 		jsonResponse, err := json.Marshal(response)
 		if err != nil {
-			gc.l.Print("response parsing error:", err)
+			gc.log.Warn("failed encode response for log", "error", err)
 		}
-		gc.l.Print("Response:", string(jsonResponse))
+		gc.log.Debug("response body", "body", string(jsonResponse))
 	}
 
 	return nil
@@ -231,15 +261,13 @@ func (gc *GladiaClient) DeleteTranscription(id string) error {
 	path := fmt.Sprintf("/v2/pre-recorded/%s", id)
 	URL := gc.baseURL + path
 
-	gc.l.Print("Try do request:", URL)
-
 	req, err := http.NewRequest("DELETE", URL, nil)
 	if err != nil {
 		return err
 	}
 	req.Header.Add("x-gladia-key", gc.token)
 
-	resp, err := gc.client.Do(req)
+	resp, err := gc.do(req)
 	if err != nil {
 		return err
 	}
@@ -258,34 +286,32 @@ func (gc *GladiaClient) DeleteTranscription(id string) error {
 
 		jsonResponse, err := json.Marshal(response)
 		if err != nil {
-			gc.l.Print("response parsing error:", err)
+			gc.log.Warn("failed encode response for log", "error", err)
 		}
-		gc.l.Print("Response:", string(jsonResponse))
+		gc.log.Debug("response body", "body", string(jsonResponse))
 	}
 
 	return nil
 }
 
 /*
-# Получить список отправленных в обработку записей
+# Получить страницу списка отправленных в обработку записей, от новых к старым
 
 	curl --request GET \
-		--url 'https://api.gladia.io/v2/pre-recorded?limit=20' \
+		--url 'https://api.gladia.io/v2/pre-recorded?offset=0&limit=20' \
 		--header 'x-gladia-key: <api-key>'
 */
-func (gc *GladiaClient) List(limit int) (*prerecorderv2.ListResponse, error) {
-	path := fmt.Sprintf("/v2/pre-recorded?limit=%d", limit)
+func (gc *GladiaClient) List(offset int, limit int) (*prerecorderv2.ListResponse, error) {
+	path := fmt.Sprintf("/v2/pre-recorded?offset=%d&limit=%d", offset, limit)
 	URL := gc.baseURL + path
 
-	gc.l.Print("Try do request:", URL)
-
 	req, err := http.NewRequest("GET", URL, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Add("x-gladia-key", gc.token)
 
-	resp, err := gc.client.Do(req)
+	resp, err := gc.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -305,9 +331,9 @@ func (gc *GladiaClient) List(limit int) (*prerecorderv2.ListResponse, error) {
 
 	jsonResponse, err := json.Marshal(responseBody)
 	if err != nil {
-		gc.l.Print("response parsing error:", err)
+		gc.log.Warn("failed encode response for log", "error", err)
 	}
-	gc.l.Print("Response:", string(jsonResponse))
+	gc.log.Debug("response body", "body", string(jsonResponse))
 
 	if err != nil {
 		return nil, err
@@ -13,5 +13,5 @@ type IHttpClient interface {
 	GetTranscriptionResult(jobId string) (*prerecorderv2.PreRecorderResultResponse, error)
 	DownloadAudioFileid(id string) error
 	DeleteTranscription(id string) error
-	List(limit int) (*prerecorderv2.ListResponse, error)
+	List(offset int, limit int) (*prerecorderv2.ListResponse, error)
 }
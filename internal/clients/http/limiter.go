@@ -0,0 +1,176 @@
+package http_client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Минимальная частота запросов, до которой limiter снижает скорость после 429
+const minRate = 0.2
+
+/*
+# Ограничение частоты и количества одновременных запросов к API
+
+Token bucket ограничивает частоту запросов, семафор - количество запросов
+в полете. Один limiter на клиент, поэтому ограничения общие для всех команд,
+работающих через этот клиент. Частота автоматически снижается при 429 и
+восстанавливается после успешных ответов, заголовки Retry-After и
+X-RateLimit-* приостанавливают отправку до указанного времени.
+*/
+type limiter struct {
+	mu          sync.Mutex
+	maxRate     float64 // настроенная частота, запросов в секунду. 0 - без ограничения
+	rate        float64 // текущая частота с учетом ответов сервера
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+
+	slots chan struct{} // nil - без ограничения
+}
+
+func newLimiter(rate float64, burst int, maxInFlight int) *limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	l := &limiter{
+		maxRate: rate,
+		rate:    rate,
+		burst:   float64(burst),
+		tokens:  float64(burst),
+		last:    time.Now(),
+	}
+	if maxInFlight > 0 {
+		l.slots = make(chan struct{}, maxInFlight)
+	}
+	return l
+}
+
+// Дождаться разрешения на запрос. Возвращает время ожидания
+func (l *limiter) wait(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+
+	for {
+		delay := l.reserve()
+		if delay == 0 {
+			break
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return time.Since(start), ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return time.Since(start), ctx.Err()
+		}
+	}
+
+	return time.Since(start), nil
+}
+
+// Забрать токен. Если токена нет, вернуть время до его появления
+func (l *limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+
+	if l.rate <= 0 {
+		return 0
+	}
+
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// Освободить место в полете после завершения запроса
+func (l *limiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// Подстроиться под ответ сервера
+func (l *limiter) update(resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	if until, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now); ok && until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil && remaining <= 0 {
+		if until, ok := parseReset(resp.Header.Get("X-RateLimit-Reset"), now); ok && until.After(l.pausedUntil) {
+			l.pausedUntil = until
+		}
+	}
+
+	if l.maxRate <= 0 {
+		return
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		l.rate /= 2
+		if l.rate < minRate {
+			l.rate = minRate
+		}
+		l.tokens = 0
+	} else if l.rate < l.maxRate {
+		l.rate *= 1.1
+		if l.rate > l.maxRate {
+			l.rate = l.maxRate
+		}
+	}
+}
+
+// Retry-After: количество секунд или HTTP дата
+func parseRetryAfter(value string, now time.Time) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if sec, err := strconv.Atoi(value); err == nil {
+		return now.Add(time.Duration(sec) * time.Second), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// X-RateLimit-Reset: количество секунд до сброса или unix время сброса
+func parseReset(value string, now time.Time) (time.Time, bool) {
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || sec < 0 {
+		return time.Time{}, false
+	}
+	if sec > 1_000_000_000 {
+		return time.Unix(sec, 0), true
+	}
+	return now.Add(time.Duration(sec) * time.Second), true
+}
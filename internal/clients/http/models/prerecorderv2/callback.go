@@ -0,0 +1,15 @@
+package prerecorderv2
+
+// Тело запроса, которое Gladia отправляет на callback url по завершении задачи
+type CallbackPayload struct {
+	ID             string         `json:"id"`                        // id задачи
+	Event          string         `json:"event"`                     // "transcription.success" или "transcription.error"
+	Payload        *Result        `json:"payload,omitempty"`         // результат транскрибации, если задача успешна
+	Error          *ErrorInfo     `json:"error,omitempty"`           // описание ошибки, если задача завершилась ошибкой
+	CustomMetadata map[string]any `json:"custom_metadata,omitempty"` // метаданные, переданные при создании задачи
+}
+
+const (
+	CallbackEventSuccess = "transcription.success"
+	CallbackEventError   = "transcription.error"
+)
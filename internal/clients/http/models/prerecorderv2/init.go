@@ -29,17 +29,71 @@ type SubtitlesConf struct {
 	Formats []string `json:"formats"` // Форматы субтитров, которые вы хотите, чтобы ваша транскрибация была отформатирована. Доступные опции: srt, vtt
 }
 
+// Конфигурация суммаризации, если summarization включено
+type SummarizationConf struct {
+	Type string `json:"type,omitempty"` // Тип резюме. Доступные опции: general, bullet_points, concise
+}
+
+// Конфигурация запросов к LLM по аудио, если audio_to_llm включено
+type AudioToLLMConf struct {
+	Prompts []string `json:"prompts"` // Список запросов, каждый выполняется над транскрибацией отдельно
+}
+
+// Термин словаря для лучшего распознавания редких слов
+type VocabularyItem struct {
+	Value     string   `json:"value"`               // Термин
+	Intensity *float64 `json:"intensity,omitempty"` // Вес термина от 0 до 1
+}
+
+// Конфигурация словаря, если custom_vocabulary включено
+type CustomVocabularyConf struct {
+	Vocabulary       []VocabularyItem `json:"vocabulary"`
+	DefaultIntensity *float64         `json:"default_intensity,omitempty"` // Вес терминов без явно указанного веса
+}
+
+// Конфигурация написаний, если custom_spelling включено
+type CustomSpellingConf struct {
+	SpellingDictionary map[string][]string `json:"spelling_dictionary"` // Каноническое написание -> варианты
+}
+
+// Конфигурация извлечения структурированных данных, если structured_data_extraction включено
+type StructuredDataConf struct {
+	Classes []string `json:"classes"` // Классы данных, которые нужно извлечь, например "order number", "product name"
+}
+
+// Конфигурация уведомления о завершении задачи, если callback включено
+type CallbackConf struct {
+	URL    string `json:"url"`              // Адрес, на который будет отправлен результат
+	Method string `json:"method,omitempty"` // HTTP метод. Доступные опции: POST, PUT
+}
+
 // Инициировать транскрибирование. POST /v2/pre-recorded
 type PreRecorderBody struct {
-	AudioUrl          string           `json:"audio_url"`                    // Uploaded audio file Gladia URL. Example: "https://api.gladia.io/file/6c09400e-23d2-4bd2-be55-96a5ececfa3b"
-	Diarization       bool             `json:"diarization"`                  // Включить автоматические определение спикеров (формат диалога)
-	DiarizationConf   *DiarizationConf `json:"diarization_config,omitempty"` // Конфиг для более точного определения спикеров
-	LangConf          *LanguageConf    `json:"language_config,omitempty"`    // Информация по исходному языку записи
-	Translation       bool             `json:"translation"`                  // Нужно ли переводить
-	TranslationConf   *TranslationConf `json:"translation_config,omitempty"` // Настройки перевода
-	Subtitle          bool             `json:"subtitles"`                    // Нужны ли субтитры
-	SubtitlesConf     *SubtitlesConf   `json:"subtitles_config,omitempty"`   // Настройки субтитров
-	SentimentAnalysis bool             `json:"sentiment_analysis"`           // Включить анализ настроений для этого аудио
+	AudioUrl          string                `json:"audio_url"`                                   // Uploaded audio file Gladia URL. Example: "https://api.gladia.io/file/6c09400e-23d2-4bd2-be55-96a5ececfa3b"
+	Diarization       bool                  `json:"diarization"`                                 // Включить автоматические определение спикеров (формат диалога)
+	DiarizationConf   *DiarizationConf      `json:"diarization_config,omitempty"`                // Конфиг для более точного определения спикеров
+	LangConf          *LanguageConf         `json:"language_config,omitempty"`                   // Информация по исходному языку записи
+	Translation       bool                  `json:"translation"`                                 // Нужно ли переводить
+	TranslationConf   *TranslationConf      `json:"translation_config,omitempty"`                // Настройки перевода
+	Subtitle          bool                  `json:"subtitles"`                                   // Нужны ли субтитры
+	SubtitlesConf     *SubtitlesConf        `json:"subtitles_config,omitempty"`                  // Настройки субтитров
+	SentimentAnalysis bool                  `json:"sentiment_analysis"`                          // Включить анализ настроений для этого аудио
+	Summarization     bool                  `json:"summarization"`                               // Сформировать резюме по транскрибации
+	SummarizationConf *SummarizationConf    `json:"summarization_config,omitempty"`              // Настройки резюме
+	Chapterization    bool                  `json:"chapterization"`                              // Разбить транскрибацию на главы
+	AudioToLLM        bool                  `json:"audio_to_llm"`                                // Выполнить пользовательские запросы к LLM по транскрибации
+	AudioToLLMConf    *AudioToLLMConf       `json:"audio_to_llm_config,omitempty"`               // Настройки запросов к LLM
+	NamedEntities     bool                  `json:"named_entity_recognition"`                    // Распознавание именованных сущностей (имена, телефоны, почта и т.д.)
+	Moderation        bool                  `json:"moderation"`                                  // Проверка транскрибации на недопустимый контент
+	CustomVocabulary  bool                  `json:"custom_vocabulary"`                           // Учитывать пользовательский словарь терминов
+	VocabularyConf    *CustomVocabularyConf `json:"custom_vocabulary_config,omitempty"`          // Словарь терминов
+	CustomSpelling    bool                  `json:"custom_spelling"`                             // Исправлять написание по пользовательскому словарю
+	SpellingConf      *CustomSpellingConf   `json:"custom_spelling_config,omitempty"`            // Словарь написаний
+	StructuredData    bool                  `json:"structured_data_extraction"`                  // Извлечь структурированные данные по классам
+	ExtractionConf    *StructuredDataConf   `json:"structured_data_extraction_config,omitempty"` // Классы для извлечения
+	Callback          bool                  `json:"callback"`                                    // Отправить результат на callback url вместо ожидания опросом
+	CallbackConf      *CallbackConf         `json:"callback_config,omitempty"`                   // Настройки callback
+	CustomMetadata    map[string]any        `json:"custom_metadata,omitempty"`                   // Пользовательские метаданные, возвращаются вместе с результатом
 }
 
 type PreRecorderInitResponse struct {
@@ -1,5 +1,12 @@
 package prerecorderv2
 
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
 type PreRecorderResultResponse struct {
 	ID            string      `json:"id"`
 	RequestID     string      `json:"request_id"`
@@ -52,21 +59,21 @@ type ReqParams struct {
 }
 
 type Result struct {
-	Metadata                 Metadata          `json:"metadata"`
-	Transcription            Transcription     `json:"transcription"`
-	Translation              TaskResult        `json:"translation"`
-	Summarization            SimpleTaskResult  `json:"summarization"`
-	Moderation               SimpleTaskResult  `json:"moderation"`
-	NamedEntityRecognition   NamedEntityResult `json:"named_entity_recognition"`
-	NameConsistency          SimpleTaskResult  `json:"name_consistency"`
-	SpeakerReidentification  SimpleTaskResult  `json:"speaker_reidentification"`
-	StructuredDataExtraction SimpleTaskResult  `json:"structured_data_extraction"`
-	SentimentAnalysis        SimpleTaskResult  `json:"sentiment_analysis"`
-	AudioToLLM               AudioToLLMResult  `json:"audio_to_llm"`
-	Sentences                SimpleTaskResult  `json:"sentences"`
-	DisplayMode              SimpleTaskResult  `json:"display_mode"`
-	Chapterization           SimpleTaskResult  `json:"chapterization"`
-	Diarization              DiarizationResult `json:"diarization"`
+	Metadata                 Metadata             `json:"metadata"`
+	Transcription            Transcription        `json:"transcription"`
+	Translation              TaskResult           `json:"translation"`
+	Summarization            SummarizationResult  `json:"summarization"`
+	Moderation               SimpleTaskResult     `json:"moderation"`
+	NamedEntityRecognition   NamedEntityResult    `json:"named_entity_recognition"`
+	NameConsistency          SimpleTaskResult     `json:"name_consistency"`
+	SpeakerReidentification  SimpleTaskResult     `json:"speaker_reidentification"`
+	StructuredDataExtraction StructuredDataResult `json:"structured_data_extraction"`
+	SentimentAnalysis        SentimentResult      `json:"sentiment_analysis"`
+	AudioToLLM               AudioToLLMResult     `json:"audio_to_llm"`
+	Sentences                SimpleTaskResult     `json:"sentences"`
+	DisplayMode              SimpleTaskResult     `json:"display_mode"`
+	Chapterization           ChapterizationResult `json:"chapterization"`
+	Diarization              DiarizationResult    `json:"diarization"`
 }
 
 // составные части структуры Result
@@ -82,6 +89,7 @@ type Transcription struct {
 	Languages      []string    `json:"languages"`
 	Utterances     []Utterance `json:"utterances"`
 	FullTranscript string      `json:"full_transcript"`
+	Subtitles      []Subtitle  `json:"subtitles,omitempty"`
 }
 
 type Utterance struct {
@@ -142,7 +150,111 @@ type NamedEntityResult struct {
 	IsEmpty  bool      `json:"is_empty"`
 	ExecTime int       `json:"exec_time"`
 	Error    ErrorInfo `json:"error"`
-	Entity   string    `json:"entity"`
+	Entity   Entities  `json:"entity"`
+}
+
+type Entity struct {
+	Type  string  `json:"entity_type"` // тип сущности, например PERSON, PHONE_NUMBER, EMAIL_ADDRESS
+	Text  string  `json:"text"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// Список сущностей. API может вернуть как массив, так и массив, сериализованный в строку
+type Entities []Entity
+
+func (e *Entities) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		if raw == "" {
+			*e = nil
+			return nil
+		}
+		data = []byte(raw)
+	}
+
+	var items []Entity
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("failed parse named entities: %w", err)
+	}
+	*e = items
+	return nil
+}
+
+type SentimentResult struct {
+	Success  bool       `json:"success"`
+	IsEmpty  bool       `json:"is_empty"`
+	ExecTime int        `json:"exec_time"`
+	Error    ErrorInfo  `json:"error"`
+	Results  Sentiments `json:"results"`
+
+	// Результаты в неожиданном формате: Results остается пустым, вызывающий код предупреждает через свой логгер
+	InvalidResults json.RawMessage `json:"-"`
+	ParseError     error           `json:"-"`
+}
+
+// Тональность необязательна, из-за неожиданного формата не должен теряться весь результат
+func (s *SentimentResult) UnmarshalJSON(data []byte) error {
+	type plain SentimentResult
+	var raw struct {
+		plain
+		Results json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*s = SentimentResult(raw.plain)
+	if len(raw.Results) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw.Results, &s.Results); err != nil {
+		s.Results, s.InvalidResults, s.ParseError = nil, raw.Results, err
+	}
+	return nil
+}
+
+// Ошибка разбора необязательных частей результата, nil - результат разобран полностью
+func (r *Result) PartialError() error {
+	if r == nil {
+		return nil
+	}
+	if err := r.SentimentAnalysis.ParseError; err != nil {
+		return fmt.Errorf("sentiment analysis results skipped: %w", err)
+	}
+	return nil
+}
+
+// Тональность и эмоция фрагмента речи
+type Sentiment struct {
+	Text      string  `json:"text"`
+	Sentiment string  `json:"sentiment"` // positive, negative, neutral или mixed
+	Emotion   string  `json:"emotion"`
+	Start     float64 `json:"start"`
+	End       float64 `json:"end"`
+	Channel   int     `json:"channel"`
+	Speaker   *int    `json:"speaker,omitempty"`
+}
+
+// Результаты анализа тональности. Как и сущности, могут прийти массивом, сериализованным в строку
+type Sentiments []Sentiment
+
+func (s *Sentiments) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		if raw == "" {
+			*s = nil
+			return nil
+		}
+		data = []byte(raw)
+	}
+
+	var items []Sentiment
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("failed parse sentiment analysis: %w", err)
+	}
+	*s = items
+	return nil
 }
 
 type AudioToLLMResult struct {
@@ -161,6 +273,89 @@ type AudioToLLMItem struct {
 	Results  LLMResult `json:"results"`
 }
 
+type StructuredDataResult struct {
+	Success  bool          `json:"success"`
+	IsEmpty  bool          `json:"is_empty"`
+	ExecTime int           `json:"exec_time"`
+	Error    ErrorInfo     `json:"error"`
+	Results  ExtractedData `json:"results"`
+}
+
+// Значение, извлеченное для одного из классов structured_data_extraction
+type ExtractedItem struct {
+	Class string `json:"class"`
+	Value string `json:"value"`
+}
+
+// Извлеченные данные. API может вернуть как список объектов, так и объект "класс -> значение(я)"
+type ExtractedData []ExtractedItem
+
+func (d *ExtractedData) UnmarshalJSON(data []byte) error {
+	var items []ExtractedItem
+	if err := json.Unmarshal(data, &items); err == nil {
+		*d = items
+		return nil
+	}
+
+	var byClass map[string]any
+	if err := json.Unmarshal(data, &byClass); err != nil {
+		return fmt.Errorf("failed parse extracted data: %w", err)
+	}
+
+	items = nil
+	for class, value := range byClass {
+		switch v := value.(type) {
+		case []any:
+			for _, item := range v {
+				items = append(items, ExtractedItem{Class: class, Value: fmt.Sprint(item)})
+			}
+		case nil:
+		default:
+			items = append(items, ExtractedItem{Class: class, Value: fmt.Sprint(v)})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Class < items[j].Class })
+
+	*d = items
+	return nil
+}
+
+// Значения одного класса
+func (d ExtractedData) Values(class string) []string {
+	var values []string
+	for _, item := range d {
+		if strings.EqualFold(item.Class, class) {
+			values = append(values, item.Value)
+		}
+	}
+	return values
+}
+
+type SummarizationResult struct {
+	Success  bool      `json:"success"`
+	IsEmpty  bool      `json:"is_empty"`
+	ExecTime int       `json:"exec_time"`
+	Error    ErrorInfo `json:"error"`
+	Results  string    `json:"results"` // текст резюме
+}
+
+type ChapterizationResult struct {
+	Success  bool      `json:"success"`
+	IsEmpty  bool      `json:"is_empty"`
+	ExecTime int       `json:"exec_time"`
+	Error    ErrorInfo `json:"error"`
+	Results  []Chapter `json:"results"`
+}
+
+type Chapter struct {
+	Headline string   `json:"headline"` // заголовок главы
+	Gist     string   `json:"gist"`     // суть в одном предложении
+	Summary  string   `json:"summary"`  // краткое содержание
+	Keywords []string `json:"keywords"`
+	Start    float64  `json:"start"`
+	End      float64  `json:"end"`
+}
+
 type LLMResult struct {
 	Prompt   string `json:"prompt"`
 	Response string `json:"response"`
@@ -7,6 +7,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"os"
+	"sync"
 )
 
 func makeMultipartBody(file *os.File, key string, value string) (*bytes.Buffer, string, error) {
@@ -24,6 +25,18 @@ func makeMultipartBody(file *os.File, key string, value string) (*bytes.Buffer,
 	return body, writer.FormDataContentType(), nil
 }
 
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
 func httpErrorParse(resp *http.Response, expectedStatusCode int) error {
 
 	if resp.StatusCode != expectedStatusCode {
@@ -5,16 +5,24 @@ import (
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"go-gladia.io-client/pkg/logger"
 )
 
 type Config struct {
-	Token   string `env:"API_KEY" env-required:"true"`
-	BaseUrl string `env:"BASE_URL" env-default:"https://api.gladia.io"`
-	IsDebug bool
+	Token      string `env:"API_KEY" env-required:"true"`
+	BaseUrl    string `env:"BASE_URL" env-default:"https://api.gladia.io"`
+	IsDebug    bool
+	HistoryDir string `env:"HISTORY_DIR"` // каталог локальной истории задач
+	LogConfig
 	Flags
 	TranscriptionConfig
 	HTTPClientConfig
 	WSClientConfig
+	CallbackConfig
+	EvalConfig
+	RetimeConfig
+	ReportConfig
+	UsageConfig
 }
 
 type (
@@ -24,15 +32,120 @@ type (
 		AwaitInterval time.Duration
 		AwaitTimeout  time.Duration
 		OutputFile    string
+		Format        string   // формат результата, по умолчанию определяется по расширению OutputFile
+		Redact        []string // типы сущностей, которые нужно скрыть в результате
+		Vocabulary    string   // файл словаря терминов
+		Spelling      string   // файл словаря написаний
+		Extract       string   // yaml файл с классами для извлечения структурированных данных
+		ExtractOutput string   // файл с извлеченными данными (.csv или .jsonl)
+		CallbackURL   string   // адрес для получения результата через callback вместо опроса
+		Meta          []string // пользовательские теги задачи key=value
+		Where         []string // фильтр задач по тегам key=value
+		Status        string   // фильтр задач по статусу
+		Limit         int      // количество выводимых задач
+		Local         bool     // искать задачи в локальной истории вместо сервера
+		ConvertTo     string   // формат, в который конвертируется сохраненный результат
+		ConvertOutput string   // файл результата конвертации, по умолчанию stdout
+		MaxLineLength int      // максимальная длина строки субтитра
+		MaxLines      int      // максимальное количество строк в субтитре
+		SpeakerLabels bool     // подписывать спикеров в субтитрах
+		SpeakerNames  string   // имена спикеров "0=Alice,1=Bob" или yaml файл
+		WordTimings   bool     // пословные таймкоды в субтитрах
+		LowConfidence float64  // порог уверенности для выделения слов
+		StyleSheet    string   // yaml файл со стилями ASS/SSA субтитров
+		FrameRate     string   // частота кадров для таймкодов TTML и EBU-STL
+		Rows          string   // строка на высказывание или на слово в csv/tsv/jsonl
+		AudioPath     string   // аудио для плеера в отчетах md и html
+		LineNumbers   bool     // нумерация строк в docx
+		ChannelNames  string   // имена каналов "0=Agent,1=Customer" или yaml файл, спикером становится канал
+		SplitChannels bool     // разделить многоканальный WAV на моно файлы перед загрузкой
+		SplitDir      string   // каталог файлов каналов, по умолчанию рядом с исходным
+		Translations  []string // языки переводов, которые пишутся отдельными файлами result.fr.srt, all - все
+		Bilingual     string   // язык перевода для двуязычных субтитров
+	}
+
+	// логирование в stderr или файл
+	LogConfig struct {
+		LogLevel  logger.Level `env:"LOG_LEVEL"`                     // debug, info, warn, error
+		LogFormat string       `env:"LOG_FORMAT" env-default:"text"` // text или json
+		LogFile   string       `env:"LOG_FILE"`                      // файл логов, пусто - stderr
+		Verbose   int          // количество флагов -v
 	}
 
 	HTTPClientConfig struct {
-		Timeout    time.Duration
-		MaxRetries uint8
+		Timeout     time.Duration
+		MaxRetries  uint8
+		RateLimit   float64 `env:"RATE_LIMIT" env-default:"5"`    // запросов в секунду, 0 - без ограничения
+		RateBurst   int     `env:"RATE_BURST" env-default:"5"`    // запросов подряд без ожидания
+		MaxInFlight int     `env:"MAX_IN_FLIGHT" env-default:"4"` // одновременных запросов, 0 - без ограничения
 	}
 
 	WSClientConfig struct{}
 
+	// сервер приема callback
+	CallbackConfig struct {
+		CallbackSecret string `env:"CALLBACK_SECRET"` // общий секрет, передается в параметре secret callback url
+		Listen         string
+		OutputDir      string
+		OutputFormats  []string
+	}
+
+	// сравнение с эталонными транскрипциями
+	EvalConfig struct {
+		Reference       string // эталон или каталог эталонов (.txt, .srt, .vtt)
+		ReferenceTurns  string // реплики спикеров эталона (.rttm) или каталог
+		EvalFormat      string // json или csv
+		EvalOutput      string // файл отчета, по умолчанию stdout
+		KeepCase        bool
+		KeepPunctuation bool
+		SpellNumbers    bool
+		DiffView        string // inline, side-by-side или none
+		NoColor         bool
+		Width           int // ширина side-by-side
+	}
+
+	// сдвиг, обрезка и объединение результатов
+	RetimeConfig struct {
+		Offset       string   // сдвиг в секундах или таймкод, может быть отрицательным
+		Scale        float64  // множитель таймкодов
+		Cut          []string // вырезаемые отрезки START-END
+		KeepRange    string   // оставляемый отрезок START-END
+		SpeakersMode string   // same или separate
+		SpeakerMap   []string // переназначение спикеров части PART:FROM=TO,...
+		AsChannels   bool     // объединить результаты каналов одной записи вместо склейки по времени
+		RetimeTo     string
+		RetimeOutput string
+	}
+
+	// аналитические отчеты по результатам
+	ReportConfig struct {
+		Timeline    string // csv файл таймлайна тональности, - для stdout
+		SparkWidth  int    // ширина спарклайна в символах
+		Fillers     string // слова-паразиты через запятую или файл, пусто - список по умолчанию
+		StatsFormat string // table или json
+		StatsOutput string // файл отчета, по умолчанию stdout
+		History     bool   // добавить к отчету завершенные задачи из локальной истории
+	}
+
+	// учет расходов
+	UsageConfig struct {
+		PricePerHour  float64  `env:"PRICE_PER_HOUR"`                // цена часа транскрибации
+		AddOnPrices   []string `env:"ADDON_PRICES"`                  // надбавки за час по функциям feature=price
+		Currency      string   `env:"CURRENCY" env-default:"USD"`    // валюта цен
+		MonthlyBudget float64  `env:"MONTHLY_BUDGET"`                // месячный бюджет, 0 - не задан
+		BudgetWarn    float64  `env:"BUDGET_WARN" env-default:"0.8"` // доля бюджета, с которой выводится предупреждение
+		UsageBy       string   // day, week, month, profile или tag:KEY
+		UsageSource   string   // local, remote или all
+		UsageCSV      string   // csv выгрузка задач
+		UsageLimit    int      // сколько задач запрашивать с сервера
+		Since         string   // начальная дата 2006-01-02
+		Until         string   // конечная дата 2006-01-02 включительно
+		MaxCost       float64  `env:"MAX_COST"`     // максимальная оценка стоимости одной загрузки, 0 - без ограничения
+		DailyBudget   float64  `env:"DAILY_BUDGET"` // дневной бюджет с учетом задач истории за сегодня, 0 - не задан
+		Features      []string // платные функции, которые будут включены при транскрибации, для оценки
+		Yes           bool     // продолжить, даже если оценка выходит за ограничения
+	}
+
 	// read from env
 	TranscriptionConfig struct {
 		Diarization       bool
@@ -44,18 +157,28 @@ type (
 		TargetLanguages   []string
 		SentimentAnalysis bool
 		InputLanguages    []string
+		Summarization     bool
+		SummaryType       string
+		Chapterization    bool
+		Prompts           []string // запросы audio-to-llm
+		NamedEntities     bool
+		Moderation        bool
+		VocabularyFile    string `env:"VOCABULARY_FILE"` // словарь терминов профиля, объединяется со словарем из флагов
+		SpellingFile      string `env:"SPELLING_FILE"`   // словарь написаний профиля, объединяется со словарем из флагов
+		ServerSpelling    bool   // исправлять написание на сервере, иначе локально после получения результата
+		BatchID           string `env:"BATCH_ID"` // id пакета, добавляется в метаданные каждой задачи
 	}
 )
 
 func LoadConfig() *Config {
 	cfg := &Config{
-		Token:          "",
-		BaseUrl:        "https://api.gladia.io",
-		AppEnv:         "DEV",
-		LogLevel:       logger.DEBUG,
-		LogPath:        "/tmp/app.log",
-		RequestTimeout: 0,
-		Transcription: Transcription{
+		Token:   "",
+		BaseUrl: "https://api.gladia.io",
+		LogConfig: LogConfig{
+			LogLevel:  logger.INFO,
+			LogFormat: logger.FormatText,
+		},
+		TranscriptionConfig: TranscriptionConfig{
 			Diarization:       false,
 			Enhanced:          true,
 			Translation:       false,
@@ -0,0 +1,109 @@
+package dictionary
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Словарь написаний: каноническое написание -> варианты, которые нужно заменить
+type Spelling map[string][]string
+
+/*
+# Загрузить словарь написаний из yaml файла
+
+	Gladia:
+	  - gladya
+	  - glad ia
+	SDK:
+	  - s d k
+*/
+func LoadSpelling(filePath string) (Spelling, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed open spelling dictionary: %w", filePath, err)
+	}
+
+	var spelling Spelling
+	if err := yaml.Unmarshal(data, &spelling); err != nil {
+		return nil, fmt.Errorf("%s: failed parse spelling dictionary: %w", filePath, err)
+	}
+
+	if err := spelling.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return spelling, nil
+}
+
+// Проверить, что у каждого написания есть варианты и один вариант не относится к нескольким написаниям
+func (s Spelling) Validate() error {
+	owners := map[string]string{}
+
+	for canonical, variants := range s {
+		if strings.TrimSpace(canonical) == "" {
+			return fmt.Errorf("empty canonical spelling")
+		}
+		if len(variants) == 0 {
+			return fmt.Errorf("%s: no variants", canonical)
+		}
+
+		for _, variant := range variants {
+			key := strings.ToLower(strings.TrimSpace(variant))
+			if key == "" {
+				return fmt.Errorf("%s: empty variant", canonical)
+			}
+			if owner, ok := owners[key]; ok && owner != canonical {
+				return fmt.Errorf("variant %q is used by both %q and %q", variant, owner, canonical)
+			}
+			owners[key] = canonical
+		}
+	}
+	return nil
+}
+
+// Объединить словари написаний. Варианты одного написания складываются без повторов
+func MergeSpelling(dicts ...Spelling) (Spelling, error) {
+	merged := Spelling{}
+
+	for _, dict := range dicts {
+		for canonical, variants := range dict {
+			for _, variant := range variants {
+				if !containsFold(merged[canonical], variant) {
+					merged[canonical] = append(merged[canonical], variant)
+				}
+			}
+		}
+	}
+
+	if err := merged.Validate(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Загрузить и объединить словари написаний из нескольких файлов. Пустые пути пропускаются
+func LoadSpellings(filePaths ...string) (Spelling, error) {
+	var dicts []Spelling
+	for _, filePath := range filePaths {
+		if filePath == "" {
+			continue
+		}
+		spelling, err := LoadSpelling(filePath)
+		if err != nil {
+			return nil, err
+		}
+		dicts = append(dicts, spelling)
+	}
+	return MergeSpelling(dicts...)
+}
@@ -0,0 +1,109 @@
+package dictionary
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Термин словаря и его вес. Нулевой Intensity означает вес по умолчанию
+type Term struct {
+	Value     string
+	Intensity *float64
+}
+
+/*
+# Загрузить словарь терминов из текстового файла
+
+Один термин на строку, через ";" можно указать вес от 0 до 1.
+Пустые строки и строки, начинающиеся с "#", пропускаются.
+
+	# продукты
+	Gladia;0.8
+	Solaria
+*/
+func LoadVocabulary(filePath string) ([]Term, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed open vocabulary: %w", filePath, err)
+	}
+	defer file.Close()
+
+	var terms []Term
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		term := Term{Value: text}
+		if idx := strings.LastIndex(text, ";"); idx >= 0 {
+			intensity, err := strconv.ParseFloat(strings.TrimSpace(text[idx+1:]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid intensity: %w", filePath, line, err)
+			}
+			term.Value = strings.TrimSpace(text[:idx])
+			term.Intensity = &intensity
+		}
+
+		if err := term.validate(); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", filePath, line, err)
+		}
+		terms = append(terms, term)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: failed read vocabulary: %w", filePath, err)
+	}
+	return terms, nil
+}
+
+func (t Term) validate() error {
+	if t.Value == "" {
+		return fmt.Errorf("empty vocabulary term")
+	}
+	if t.Intensity != nil && (*t.Intensity < 0 || *t.Intensity > 1) {
+		return fmt.Errorf("%s: intensity must be between 0 and 1, got %g", t.Value, *t.Intensity)
+	}
+	return nil
+}
+
+// Объединить словари. Термины сравниваются без учета регистра, вес из более позднего словаря имеет приоритет
+func MergeVocabulary(lists ...[]Term) []Term {
+	var merged []Term
+	index := map[string]int{}
+
+	for _, list := range lists {
+		for _, term := range list {
+			key := strings.ToLower(term.Value)
+			if idx, ok := index[key]; ok {
+				if term.Intensity != nil {
+					merged[idx].Intensity = term.Intensity
+				}
+				continue
+			}
+			index[key] = len(merged)
+			merged = append(merged, term)
+		}
+	}
+	return merged
+}
+
+// Загрузить и объединить словари терминов из нескольких файлов. Пустые пути пропускаются
+func LoadVocabularies(filePaths ...string) ([]Term, error) {
+	var lists [][]Term
+	for _, filePath := range filePaths {
+		if filePath == "" {
+			continue
+		}
+		terms, err := LoadVocabulary(filePath)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, terms)
+	}
+	return MergeVocabulary(lists...), nil
+}
@@ -0,0 +1,255 @@
+package eval
+
+// Операция выравнивания
+type OpKind byte
+
+const (
+	OpEqual OpKind = iota
+	OpSubstitute
+	OpInsert // слово есть только в гипотезе
+	OpDelete // слово есть только в эталоне
+)
+
+// Шаг выравнивания: индексы слов в эталоне и гипотезе, -1 если слова нет
+type Op struct {
+	Kind OpKind
+	Ref  int
+	Hyp  int
+}
+
+// Количество ошибок по типам
+type Counts struct {
+	Hits          int `json:"hits"`
+	Substitutions int `json:"substitutions"`
+	Insertions    int `json:"insertions"`
+	Deletions     int `json:"deletions"`
+}
+
+func (c Counts) Errors() int {
+	return c.Substitutions + c.Insertions + c.Deletions
+}
+
+// Количество слов эталона
+func (c Counts) RefLength() int {
+	return c.Hits + c.Substitutions + c.Deletions
+}
+
+// Доля ошибок относительно длины эталона
+func (c Counts) Rate() float64 {
+	if n := c.RefLength(); n > 0 {
+		return float64(c.Errors()) / float64(n)
+	}
+	if c.Insertions > 0 {
+		return 1
+	}
+	return 0
+}
+
+func (c *Counts) Add(o Counts) {
+	c.Hits += o.Hits
+	c.Substitutions += o.Substitutions
+	c.Insertions += o.Insertions
+	c.Deletions += o.Deletions
+}
+
+// Предел размера таблицы шагов полного выравнивания (байт), длинные записи выравниваются по частям
+const maxAlignCells = 16 << 20
+
+/*
+# Выравнивание по Левенштейну
+
+Минимальное количество замен, вставок и удалений, превращающих эталон
+в гипотезу. При равной стоимости предпочитается совпадение, затем замена,
+поэтому выравнивание совпадает с sclite/jiwer на типичных примерах.
+
+Таблица шагов занимает (n+1)*(m+1) байт, поэтому для длинных записей
+используется алгоритм Хиршберга: эталон делится пополам, точка деления
+гипотезы находится по двум строкам стоимостей, и половины выравниваются
+независимо, пока таблица не станет меньше maxAlignCells. Память линейная,
+время остается O(n*m).
+*/
+func Align(ref, hyp []string) []Op {
+	return align(ref, hyp, maxAlignCells)
+}
+
+func align(ref, hyp []string, limit int) []Op {
+	ops := make([]Op, 0, max(len(ref), len(hyp)))
+	return alignSplit(ops, ref, hyp, 0, 0, limit)
+}
+
+// Дописать в ops выравнивание ref и hyp, индексы сдвигаются на refOff и hypOff
+func alignSplit(ops []Op, ref, hyp []string, refOff, hypOff, limit int) []Op {
+	n, m := len(ref), len(hyp)
+	if n <= 1 || (n+1)*(m+1) <= limit {
+		for _, op := range alignFull(ref, hyp) {
+			if op.Ref >= 0 {
+				op.Ref += refOff
+			}
+			if op.Hyp >= 0 {
+				op.Hyp += hypOff
+			}
+			ops = append(ops, op)
+		}
+		return ops
+	}
+
+	mid := n / 2
+	forward := lastCosts(ref[:mid], hyp)
+	backward := lastCosts(reversed(ref[mid:]), reversed(hyp))
+
+	split := 0
+	for j := 1; j <= m; j++ {
+		if forward[j]+backward[m-j] < forward[split]+backward[m-split] {
+			split = j
+		}
+	}
+
+	ops = alignSplit(ops, ref[:mid], hyp[:split], refOff, hypOff, limit)
+	return alignSplit(ops, ref[mid:], hyp[split:], refOff+mid, hypOff+split, limit)
+}
+
+// Стоимости выравнивания всего ref с каждым префиксом hyp
+func lastCosts(ref, hyp []string) []int {
+	m := len(hyp)
+	prev := make([]int, m+1)
+	cur := make([]int, m+1)
+	for j := 0; j <= m; j++ {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ref); i++ {
+		cur[0] = i
+		for j := 1; j <= m; j++ {
+			cost := 1
+			if ref[i-1] == hyp[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j-1]+cost, prev[j]+1, cur[j-1]+1)
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+func reversed(words []string) []string {
+	out := make([]string, len(words))
+	for i, w := range words {
+		out[len(words)-1-i] = w
+	}
+	return out
+}
+
+// Выравнивание с полной таблицей шагов
+func alignFull(ref, hyp []string) []Op {
+	n, m := len(ref), len(hyp)
+
+	// стоимость считается построчно, для восстановления пути хранятся только шаги
+	const (
+		stepDiag byte = iota
+		stepUp        // удаление
+		stepLeft      // вставка
+	)
+	steps := make([]byte, (n+1)*(m+1))
+	prev := make([]int, m+1)
+	cur := make([]int, m+1)
+
+	for j := 0; j <= m; j++ {
+		prev[j] = j
+		steps[j] = stepLeft
+	}
+	for i := 1; i <= n; i++ {
+		cur[0] = i
+		steps[i*(m+1)] = stepUp
+		for j := 1; j <= m; j++ {
+			cost := 1
+			if ref[i-1] == hyp[j-1] {
+				cost = 0
+			}
+			best, step := prev[j-1]+cost, stepDiag
+			if d := prev[j] + 1; d < best {
+				best, step = d, stepUp
+			}
+			if ins := cur[j-1] + 1; ins < best {
+				best, step = ins, stepLeft
+			}
+			cur[j] = best
+			steps[i*(m+1)+j] = step
+		}
+		prev, cur = cur, prev
+	}
+
+	ops := make([]Op, 0, max(n, m))
+	for i, j := n, m; i > 0 || j > 0; {
+		switch steps[i*(m+1)+j] {
+		case stepDiag:
+			kind := OpSubstitute
+			if ref[i-1] == hyp[j-1] {
+				kind = OpEqual
+			}
+			ops = append(ops, Op{Kind: kind, Ref: i - 1, Hyp: j - 1})
+			i, j = i-1, j-1
+		case stepUp:
+			ops = append(ops, Op{Kind: OpDelete, Ref: i - 1, Hyp: -1})
+			i--
+		default:
+			ops = append(ops, Op{Kind: OpInsert, Ref: -1, Hyp: j - 1})
+			j--
+		}
+	}
+
+	for l, r := 0, len(ops)-1; l < r; l, r = l+1, r-1 {
+		ops[l], ops[r] = ops[r], ops[l]
+	}
+	return ops
+}
+
+// Подсчитать ошибки по выравниванию
+func Count(ops []Op) Counts {
+	var c Counts
+	for _, op := range ops {
+		switch op.Kind {
+		case OpEqual:
+			c.Hits++
+		case OpSubstitute:
+			c.Substitutions++
+		case OpInsert:
+			c.Insertions++
+		case OpDelete:
+			c.Deletions++
+		}
+	}
+	return c
+}
+
+/*
+# Ошибки на уровне символов
+
+Считаются по выравниванию слов: для замененного слова - расстояние
+Левенштейна между словами, для вставленного или удаленного - его длина.
+Так CER не требует квадратичного выравнивания всех символов длинной записи,
+а на практике расходится с точным значением на доли процента.
+*/
+func CharCounts(ref, hyp []string, ops []Op) Counts {
+	var c Counts
+	for _, op := range ops {
+		switch op.Kind {
+		case OpEqual:
+			c.Hits += len([]rune(ref[op.Ref]))
+		case OpSubstitute:
+			c.Add(Count(Align(chars(ref[op.Ref]), chars(hyp[op.Hyp]))))
+		case OpInsert:
+			c.Insertions += len([]rune(hyp[op.Hyp]))
+		case OpDelete:
+			c.Deletions += len([]rune(ref[op.Ref]))
+		}
+	}
+	return c
+}
+
+func chars(word string) []string {
+	runes := []rune(word)
+	out := make([]string, len(runes))
+	for i, r := range runes {
+		out[i] = string(r)
+	}
+	return out
+}
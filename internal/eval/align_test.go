@@ -0,0 +1,63 @@
+package eval
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestAlign(t *testing.T) {
+	ref := strings.Fields("the cat sat on the mat")
+	hyp := strings.Fields("the cat sat at the mat today")
+
+	c := Count(Align(ref, hyp))
+	if want := (Counts{Hits: 5, Substitutions: 1, Insertions: 1}); c != want {
+		t.Errorf("got %+v, want %+v", c, want)
+	}
+}
+
+// Выравнивание по частям дает то же число ошибок, что и полная таблица
+func TestAlignSplitMatchesFull(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	vocab := strings.Fields("a b c d e f")
+	words := func(n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = vocab[rnd.Intn(len(vocab))]
+		}
+		return out
+	}
+
+	for range 200 {
+		ref, hyp := words(rnd.Intn(60)), words(rnd.Intn(60))
+		full := Count(alignFull(ref, hyp))
+		ops := align(ref, hyp, 16)
+
+		if got := Count(ops); got.Errors() != full.Errors() || got.RefLength() != len(ref) {
+			t.Fatalf("ref %v hyp %v: split %+v, full %+v", ref, hyp, got, full)
+		}
+
+		// все слова обеих сторон встречаются по порядку ровно один раз
+		i, j := 0, 0
+		for _, op := range ops {
+			if op.Ref >= 0 {
+				if op.Ref != i {
+					t.Fatalf("ref index %d, want %d", op.Ref, i)
+				}
+				i++
+			}
+			if op.Hyp >= 0 {
+				if op.Hyp != j {
+					t.Fatalf("hyp index %d, want %d", op.Hyp, j)
+				}
+				j++
+			}
+			if op.Kind == OpEqual && ref[op.Ref] != hyp[op.Hyp] {
+				t.Fatalf("equal op on %q and %q", ref[op.Ref], hyp[op.Hyp])
+			}
+		}
+		if i != len(ref) || j != len(hyp) {
+			t.Fatalf("aligned %d/%d ref and %d/%d hyp words", i, len(ref), j, len(hyp))
+		}
+	}
+}
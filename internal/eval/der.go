@@ -0,0 +1,136 @@
+package eval
+
+import (
+	"sort"
+)
+
+// Составляющие ошибки диаризации в секундах
+type DiarizationCounts struct {
+	Speech      float64 `json:"speech"`       // суммарная речь в эталоне
+	Missed      float64 `json:"missed"`       // речь эталона, которой нет в гипотезе
+	FalseAlarm  float64 `json:"false_alarm"`  // речь гипотезы, которой нет в эталоне
+	Confusion   float64 `json:"confusion"`    // речь, приписанная не тому спикеру
+	SpeakersRef int     `json:"speakers_ref"` // количество спикеров в эталоне
+	SpeakersHyp int     `json:"speakers_hyp"` // количество спикеров в гипотезе
+}
+
+// Diarization error rate: (missed + false alarm + confusion) / speech
+func (c DiarizationCounts) Rate() float64 {
+	if c.Speech <= 0 {
+		return 0
+	}
+	return (c.Missed + c.FalseAlarm + c.Confusion) / c.Speech
+}
+
+func (c *DiarizationCounts) Add(o DiarizationCounts) {
+	c.Speech += o.Speech
+	c.Missed += o.Missed
+	c.FalseAlarm += o.FalseAlarm
+	c.Confusion += o.Confusion
+	c.SpeakersRef += o.SpeakersRef
+	c.SpeakersHyp += o.SpeakersHyp
+}
+
+/*
+# Ошибка диаризации
+
+Метки спикеров эталона и гипотезы сопоставляются жадно по наибольшему
+общему времени речи, затем время делится на отрезки между границами
+реплик и на каждом отрезке считаются пропуски, ложные срабатывания и
+путаница спикеров, как в NIST md-eval без допуска на границах (collar 0).
+*/
+func Diarization(ref, hyp []SpeakerTurn) DiarizationCounts {
+	mapping := mapSpeakers(ref, hyp)
+
+	bounds := make([]float64, 0, 2*(len(ref)+len(hyp)))
+	for _, t := range append(append([]SpeakerTurn{}, ref...), hyp...) {
+		bounds = append(bounds, t.Start, t.End)
+	}
+	sort.Float64s(bounds)
+
+	counts := DiarizationCounts{SpeakersRef: len(speakers(ref)), SpeakersHyp: len(speakers(hyp))}
+	for i := 1; i < len(bounds); i++ {
+		start, end := bounds[i-1], bounds[i]
+		if end <= start {
+			continue
+		}
+		mid := (start + end) / 2
+		dur := end - start
+
+		refActive := activeSpeakers(ref, mid)
+		hypActive := activeSpeakers(hyp, mid)
+		nRef, nHyp := len(refActive), len(hypActive)
+
+		correct := 0
+		for h := range hypActive {
+			if r, ok := mapping[h]; ok && refActive[r] {
+				correct++
+			}
+		}
+
+		counts.Speech += dur * float64(nRef)
+		if nRef > nHyp {
+			counts.Missed += dur * float64(nRef-nHyp)
+		} else {
+			counts.FalseAlarm += dur * float64(nHyp-nRef)
+		}
+		counts.Confusion += dur * float64(min(nRef, nHyp)-correct)
+	}
+	return counts
+}
+
+// Соответствие спикеров гипотезы спикерам эталона
+func mapSpeakers(ref, hyp []SpeakerTurn) map[string]string {
+	type pair struct {
+		ref, hyp string
+		overlap  float64
+	}
+	overlaps := map[[2]string]float64{}
+	for _, r := range ref {
+		for _, h := range hyp {
+			if o := min(r.End, h.End) - max(r.Start, h.Start); o > 0 {
+				overlaps[[2]string{r.Speaker, h.Speaker}] += o
+			}
+		}
+	}
+
+	pairs := make([]pair, 0, len(overlaps))
+	for key, o := range overlaps {
+		pairs = append(pairs, pair{ref: key[0], hyp: key[1], overlap: o})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].overlap != pairs[j].overlap {
+			return pairs[i].overlap > pairs[j].overlap
+		}
+		return pairs[i].ref+"\x00"+pairs[i].hyp < pairs[j].ref+"\x00"+pairs[j].hyp
+	})
+
+	mapping := map[string]string{}
+	usedRef := map[string]bool{}
+	for _, p := range pairs {
+		if _, ok := mapping[p.hyp]; ok || usedRef[p.ref] {
+			continue
+		}
+		mapping[p.hyp] = p.ref
+		usedRef[p.ref] = true
+	}
+	return mapping
+}
+
+func activeSpeakers(turns []SpeakerTurn, at float64) map[string]bool {
+	active := map[string]bool{}
+	for _, t := range turns {
+		if t.Start <= at && at < t.End {
+			active[t.Speaker] = true
+		}
+	}
+	return active
+}
+
+func speakers(turns []SpeakerTurn) map[string]bool {
+	set := map[string]bool{}
+	for _, t := range turns {
+		set[t.Speaker] = true
+	}
+	return set
+}
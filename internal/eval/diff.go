@@ -0,0 +1,212 @@
+package eval
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Слово транскрипта с таймкодами и спикером
+type TimedWord struct {
+	Text      string
+	Start     float64
+	End       float64
+	Speaker   *int
+	Utterance int // номер высказывания
+}
+
+// Слова результата по порядку, без пословных таймкодов время высказывания делится поровну
+func Words(res *prerecorderv2.Result) []TimedWord {
+	var words []TimedWord
+	for idx, u := range res.Transcription.Utterances {
+		if len(u.Words) > 0 {
+			for _, w := range u.Words {
+				if text := strings.TrimSpace(w.Word); text != "" {
+					words = append(words, TimedWord{Text: text, Start: w.Start, End: w.End, Speaker: u.Speaker, Utterance: idx})
+				}
+			}
+			continue
+		}
+
+		fields := strings.Fields(u.Text)
+		step := (u.End - u.Start) / float64(max(len(fields), 1))
+		for i, field := range fields {
+			words = append(words, TimedWord{
+				Text:      field,
+				Start:     u.Start + step*float64(i),
+				End:       u.Start + step*float64(i+1),
+				Speaker:   u.Speaker,
+				Utterance: idx,
+			})
+		}
+	}
+	return words
+}
+
+// Сдвиг таймкодов совпавших слов в секундах (B - A)
+type Drift struct {
+	Words     int     `json:"words"`
+	Mean      float64 `json:"mean"`       // средний сдвиг начала слова со знаком
+	MeanAbs   float64 `json:"mean_abs"`   // средний модуль сдвига
+	Median    float64 `json:"median_abs"` // медиана модуля сдвига
+	Max       float64 `json:"max_abs"`
+	MaxAt     float64 `json:"max_at"` // время слова A с наибольшим сдвигом
+	EndMean   float64 `json:"end_mean"`
+	DurationA float64 `json:"duration_a"`
+	DurationB float64 `json:"duration_b"`
+}
+
+// Переназначение спикеров среди совпавших слов
+type SpeakerChange struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Words int    `json:"words"`
+}
+
+type SpeakerStats struct {
+	Compared   int               `json:"compared"`   // совпавших слов, у которых известны оба спикера
+	Reassigned int               `json:"reassigned"` // слов, отданных другому спикеру с учетом переименования
+	Mapping    map[string]string `json:"mapping"`    // соответствие спикеров A спикерам B
+	Changes    []SpeakerChange   `json:"changes"`    // все пары спикеров A -> B по убыванию
+}
+
+// Сравнение двух транскриптов
+type Comparison struct {
+	A        []TimedWord  `json:"-"`
+	B        []TimedWord  `json:"-"`
+	Ops      []Op         `json:"-"`
+	Counts   Counts       `json:"counts"`
+	Drift    Drift        `json:"drift"`
+	Speakers SpeakerStats `json:"speakers"`
+}
+
+/*
+# Сравнить два результата
+
+Слова выравниваются после нормализации, слова из одной пунктуации
+отбрасываются. A считается эталоном: удаления - слова, пропавшие в B,
+вставки - появившиеся. Сдвиг и переназначение спикеров считаются только
+по совпавшим словам.
+*/
+func Compare(a, b *prerecorderv2.Result, n Normalization) Comparison {
+	// слова из одной пунктуации после нормализации пустые и, как в WER, не сравниваются
+	normalized := func(res *prerecorderv2.Result) ([]TimedWord, []string) {
+		var words []TimedWord
+		var keys []string
+		for _, w := range Words(res) {
+			if key := strings.Join(Normalize(w.Text, n), " "); key != "" {
+				words, keys = append(words, w), append(keys, key)
+			}
+		}
+		return words, keys
+	}
+
+	var c Comparison
+	var keysA, keysB []string
+	c.A, keysA = normalized(a)
+	c.B, keysB = normalized(b)
+	c.Ops = Align(keysA, keysB)
+	c.Counts = Count(c.Ops)
+	c.Drift = drift(c.A, c.B, c.Ops)
+	c.Drift.DurationA, c.Drift.DurationB = a.Metadata.AudioDuration, b.Metadata.AudioDuration
+	c.Speakers = speakerStats(c.A, c.B, c.Ops)
+	return c
+}
+
+func drift(a, b []TimedWord, ops []Op) Drift {
+	var d Drift
+	var abs []float64
+	sum, sumEnd := 0.0, 0.0
+
+	for _, op := range ops {
+		if op.Kind != OpEqual {
+			continue
+		}
+		delta := b[op.Hyp].Start - a[op.Ref].Start
+		sum += delta
+		sumEnd += b[op.Hyp].End - a[op.Ref].End
+		abs = append(abs, math.Abs(delta))
+		if math.Abs(delta) > d.Max {
+			d.Max, d.MaxAt = math.Abs(delta), a[op.Ref].Start
+		}
+	}
+	if len(abs) == 0 {
+		return d
+	}
+
+	d.Words = len(abs)
+	d.Mean = sum / float64(len(abs))
+	d.EndMean = sumEnd / float64(len(abs))
+	for _, v := range abs {
+		d.MeanAbs += v
+	}
+	d.MeanAbs /= float64(len(abs))
+
+	sort.Float64s(abs)
+	if mid := len(abs) / 2; len(abs)%2 == 1 {
+		d.Median = abs[mid]
+	} else {
+		d.Median = (abs[mid-1] + abs[mid]) / 2
+	}
+	return d
+}
+
+// Номера спикеров могут поменяться между запусками, поэтому сначала спикеры сопоставляются
+// по наибольшему количеству общих слов, а переназначенными считаются слова вне этого соответствия
+func speakerStats(a, b []TimedWord, ops []Op) SpeakerStats {
+	stats := SpeakerStats{Mapping: map[string]string{}}
+	pairs := map[[2]string]int{}
+
+	for _, op := range ops {
+		if op.Kind != OpEqual || a[op.Ref].Speaker == nil || b[op.Hyp].Speaker == nil {
+			continue
+		}
+		pairs[[2]string{speakerKey(a[op.Ref].Speaker), speakerKey(b[op.Hyp].Speaker)}]++
+		stats.Compared++
+	}
+
+	for key, count := range pairs {
+		stats.Changes = append(stats.Changes, SpeakerChange{From: key[0], To: key[1], Words: count})
+	}
+	sort.Slice(stats.Changes, func(i, j int) bool {
+		ci, cj := stats.Changes[i], stats.Changes[j]
+		if ci.Words != cj.Words {
+			return ci.Words > cj.Words
+		}
+		return ci.From+"\x00"+ci.To < cj.From+"\x00"+cj.To
+	})
+
+	usedB := map[string]bool{}
+	for _, ch := range stats.Changes {
+		if _, ok := stats.Mapping[ch.From]; ok || usedB[ch.To] {
+			continue
+		}
+		stats.Mapping[ch.From] = ch.To
+		usedB[ch.To] = true
+	}
+	for _, ch := range stats.Changes {
+		if stats.Mapping[ch.From] != ch.To {
+			stats.Reassigned += ch.Words
+		}
+	}
+	return stats
+}
+
+// Отдано ли слово другому спикеру с учетом сопоставления спикеров
+func (s SpeakerStats) IsReassigned(a, b *int) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	mapped, ok := s.Mapping[speakerKey(a)]
+	return ok && mapped != speakerKey(b)
+}
+
+func speakerKey(speaker *int) string {
+	if speaker == nil {
+		return ""
+	}
+	return strconv.Itoa(*speaker)
+}
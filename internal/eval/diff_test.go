@@ -0,0 +1,31 @@
+package eval
+
+import (
+	"testing"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+func wordsResult(words ...string) *prerecorderv2.Result {
+	res := &prerecorderv2.Result{}
+	var ws []prerecorderv2.Word
+	for i, w := range words {
+		ws = append(ws, prerecorderv2.Word{Word: " " + w, Start: float64(i), End: float64(i) + 0.5})
+	}
+	res.Transcription.Utterances = []prerecorderv2.Utterance{{Words: ws}}
+	return res
+}
+
+// Отдельные знаки пунктуации не считаются ни вставками, ни совпадениями
+func TestComparePunctuationWords(t *testing.T) {
+	a := wordsResult("hello", "-", "world")
+	b := wordsResult("hello", "world", "?")
+
+	c := Compare(a, b, Normalization{})
+	if want := (Counts{Hits: 2}); c.Counts != want {
+		t.Errorf("got %+v, want %+v", c.Counts, want)
+	}
+	if len(c.A) != 2 || len(c.B) != 2 {
+		t.Errorf("punctuation words kept: A %+v, B %+v", c.A, c.B)
+	}
+}
@@ -0,0 +1,139 @@
+package eval
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Нормализация текста перед сравнением
+type Normalization struct {
+	KeepCase        bool // не приводить к нижнему регистру
+	KeepPunctuation bool // не удалять пунктуацию
+	SpellNumbers    bool // записывать числа словами (английский)
+}
+
+// Разбить текст на слова с учетом нормализации
+func Normalize(text string, n Normalization) []string {
+	if !n.KeepCase {
+		text = strings.ToLower(text)
+	}
+
+	var words []string
+	for _, field := range strings.Fields(text) {
+		if n.SpellNumbers {
+			if spelled, ok := spellNumber(field); ok {
+				if !n.KeepCase {
+					spelled = strings.ToLower(spelled)
+				}
+				words = append(words, strings.Fields(spelled)...)
+				continue
+			}
+		}
+		if !n.KeepPunctuation {
+			field = stripPunctuation(field)
+		}
+		words = append(words, strings.Fields(field)...)
+	}
+	return words
+}
+
+// Удалить пунктуацию и символы, апостроф внутри слова сохраняется (don't), дефис разделяет слова
+func stripPunctuation(word string) string {
+	runes := []rune(word)
+	var b strings.Builder
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == '–' || r == '—' || r == '/':
+			b.WriteRune(' ')
+		case (r == '\'' || r == '’') && i > 0 && i < len(runes)-1 && unicode.IsLetter(runes[i-1]) && unicode.IsLetter(runes[i+1]):
+			b.WriteRune('\'')
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+var (
+	smallNumbers = []string{
+		"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
+		"eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen",
+	}
+	tensNumbers = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	scales      = []struct {
+		value int64
+		name  string
+	}{{1_000_000_000, "billion"}, {1_000_000, "million"}, {1_000, "thousand"}}
+)
+
+// Число словами: 42 -> forty two, 1,500 -> one thousand five hundred, 3.5 -> three point five
+func spellNumber(token string) (string, bool) {
+	token = strings.TrimRightFunc(token, func(r rune) bool { return unicode.IsPunct(r) && r != '%' })
+	token = strings.TrimLeftFunc(token, unicode.IsPunct)
+
+	percent := strings.HasSuffix(token, "%")
+	token = strings.TrimSuffix(token, "%")
+
+	intPart, fracPart, hasFrac := strings.Cut(token, ".")
+	intPart = strings.ReplaceAll(intPart, ",", "")
+	if intPart == "" || !isDigits(intPart) || hasFrac && (fracPart == "" || !isDigits(fracPart)) {
+		return "", false
+	}
+
+	value, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || value >= 1_000_000_000_000 {
+		return "", false
+	}
+
+	words := integerWords(value)
+	if hasFrac {
+		words = append(words, "point")
+		for _, d := range fracPart {
+			words = append(words, smallNumbers[d-'0'])
+		}
+	}
+	if percent {
+		words = append(words, "percent")
+	}
+	return strings.Join(words, " "), true
+}
+
+func integerWords(n int64) []string {
+	if n < 20 {
+		return []string{smallNumbers[n]}
+	}
+
+	var words []string
+	for _, scale := range scales {
+		if n >= scale.value {
+			words = append(words, integerWords(n/scale.value)...)
+			words = append(words, scale.name)
+			n %= scale.value
+		}
+	}
+	if n >= 100 {
+		words = append(words, smallNumbers[n/100], "hundred")
+		n %= 100
+	}
+	switch {
+	case n >= 20:
+		words = append(words, tensNumbers[n/10])
+		if n%10 > 0 {
+			words = append(words, smallNumbers[n%10])
+		}
+	case n > 0:
+		words = append(words, smallNumbers[n])
+	}
+	return words
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,215 @@
+package eval
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Отрезок речи спикера
+type SpeakerTurn struct {
+	Speaker string
+	Start   float64
+	End     float64
+}
+
+// Эталонная разметка: текст и, если известны, реплики спикеров
+type Reference struct {
+	Text  string
+	Turns []SpeakerTurn
+}
+
+var (
+	cueTimingRe  = regexp.MustCompile(`^\s*(\S+)\s+-->\s+(\S+)`)
+	voiceTagRe   = regexp.MustCompile(`^<v(?:\.[\w.-]+)?\s+([^>]+)>`)
+	speakerRe    = regexp.MustCompile(`^([\p{L}\p{N}][\p{L}\p{N} ._'-]{0,39}):\s+`)
+	markupTagsRe = regexp.MustCompile(`<[^>]*>|\{[^}]*\}`)
+)
+
+/*
+# Загрузить эталон
+
+Формат определяется по расширению:
+  - .txt - только текст;
+  - .srt, .vtt - текст и реплики, если субтитры подписаны (<v Alice> или "Alice: ...",
+    такой префикс считается подписью, только если повторяется в нескольких субтитрах);
+  - .rttm - только реплики (NIST RTTM, строки SPEAKER).
+*/
+func LoadReference(path string) (*Reference, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".srt", ".vtt":
+		return loadSubtitleReference(path)
+	case ".rttm":
+		turns, err := LoadRTTM(path)
+		if err != nil {
+			return nil, err
+		}
+		return &Reference{Turns: turns}, nil
+	default:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed read reference: %w", path, err)
+		}
+		return &Reference{Text: string(data)}, nil
+	}
+}
+
+// Строка текста субтитра с возможной подписью спикера
+type cueLine struct {
+	cue     int
+	turn    SpeakerTurn
+	voice   string // спикер из тега <v Alice>
+	label   string // префикс "Alice: ", подписью считается только если повторяется в нескольких cue
+	text    string
+	unlabel string // текст без префикса
+}
+
+func loadSubtitleReference(path string) (*Reference, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed read reference: %w", path, err)
+	}
+	defer file.Close()
+
+	var lines []cueLine
+	var cur SpeakerTurn
+	cue := -1
+	inCue, skipBlock := false, false
+
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+
+		switch {
+		case line == "":
+			inCue, skipBlock = false, false
+			continue
+		case skipBlock:
+			continue
+		case !inCue && (line == "WEBVTT" || strings.HasPrefix(line, "WEBVTT ") || strings.HasPrefix(line, "NOTE") || line == "STYLE" || line == "REGION"):
+			skipBlock = true
+			continue
+		}
+
+		if m := cueTimingRe.FindStringSubmatch(line); m != nil {
+			start, err := parseTimestamp(m[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+			}
+			end, err := parseTimestamp(m[2])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+			}
+			inCue = true
+			cue++
+			cur = SpeakerTurn{Start: start, End: end}
+			continue
+		}
+		if !inCue {
+			// номер субтитра srt или идентификатор cue vtt
+			continue
+		}
+
+		l := cueLine{cue: cue, turn: cur}
+		if m := voiceTagRe.FindStringSubmatch(line); m != nil {
+			l.voice = strings.TrimSpace(m[1])
+		} else if m := speakerRe.FindStringSubmatch(line); m != nil {
+			l.label = strings.TrimSpace(m[1])
+			l.unlabel = strings.TrimSpace(markupTagsRe.ReplaceAllString(line[len(m[0]):], ""))
+		}
+		l.text = strings.TrimSpace(markupTagsRe.ReplaceAllString(line, ""))
+		lines = append(lines, l)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: failed read reference: %w", path, err)
+	}
+
+	// "Note: ..." в одном cue - обычный текст, подпись спикера повторяется в разных cue
+	labelCues := map[string]map[int]bool{}
+	for _, l := range lines {
+		if l.label != "" {
+			if labelCues[l.label] == nil {
+				labelCues[l.label] = map[int]bool{}
+			}
+			labelCues[l.label][l.cue] = true
+		}
+	}
+
+	ref := &Reference{}
+	var text []string
+	turnCue := -1
+	for _, l := range lines {
+		speaker, line := l.voice, l.text
+		if l.label != "" && len(labelCues[l.label]) > 1 {
+			speaker, line = l.label, l.unlabel
+		}
+		if speaker != "" && turnCue != l.cue {
+			l.turn.Speaker = speaker
+			ref.Turns = append(ref.Turns, l.turn)
+			turnCue = l.cue
+		}
+		if line != "" {
+			text = append(text, line)
+		}
+	}
+
+	ref.Text = strings.Join(text, " ")
+	return ref, nil
+}
+
+// Реплики из файла RTTM: SPEAKER <file> <channel> <start> <duration> <NA> <NA> <speaker> ...
+func LoadRTTM(path string) ([]SpeakerTurn, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed read rttm: %w", path, err)
+	}
+	defer file.Close()
+
+	var turns []SpeakerTurn
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != "SPEAKER" {
+			continue
+		}
+		if len(fields) < 8 {
+			return nil, fmt.Errorf("%s:%d: expected at least 8 fields", path, lineNo)
+		}
+		start, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: bad start: %w", path, lineNo, err)
+		}
+		duration, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: bad duration: %w", path, lineNo, err)
+		}
+		turns = append(turns, SpeakerTurn{Speaker: fields[7], Start: start, End: start + duration})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: failed read rttm: %w", path, err)
+	}
+	return turns, nil
+}
+
+// Таймкод субтитров: 00:01:02,500, 00:01:02.500 или 01:02.500
+func parseTimestamp(value string) (float64, error) {
+	value = strings.Replace(value, ",", ".", 1)
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("bad timestamp %q", value)
+	}
+
+	total := 0.0
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, fmt.Errorf("bad timestamp %q", value)
+		}
+		total = total*60 + v
+	}
+	return total, nil
+}
@@ -0,0 +1,70 @@
+package eval
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func loadTestReference(t *testing.T, name, data string) *Reference {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := LoadReference(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func TestSubtitleReferenceSpeakers(t *testing.T) {
+	ref := loadTestReference(t, "ref.srt", `1
+00:00:01,000 --> 00:00:02,000
+Alice: Hello there.
+
+2
+00:00:02,000 --> 00:00:03,000
+Bob: Hi.
+
+3
+00:00:03,000 --> 00:00:04,000
+Alice: Note: the meeting moved.
+
+4
+00:00:04,000 --> 00:00:05,000
+Bob: Okay.
+`)
+
+	if want := "Hello there. Hi. Note: the meeting moved. Okay."; ref.Text != want {
+		t.Errorf("text %q, want %q", ref.Text, want)
+	}
+	var speakers []string
+	for _, turn := range ref.Turns {
+		speakers = append(speakers, turn.Speaker)
+	}
+	if want := []string{"Alice", "Bob", "Alice", "Bob"}; !slices.Equal(speakers, want) {
+		t.Errorf("speakers %v, want %v", speakers, want)
+	}
+}
+
+// Префикс, который встречается один раз, остается частью текста
+func TestSubtitleReferenceWordPrefix(t *testing.T) {
+	ref := loadTestReference(t, "ref.vtt", `WEBVTT
+
+00:00:01.000 --> 00:00:02.000
+Warning: the floor is wet.
+
+00:00:02.000 --> 00:00:03.000
+Answer: yes, we checked it.
+`)
+
+	if want := "Warning: the floor is wet. Answer: yes, we checked it."; ref.Text != want {
+		t.Errorf("text %q, want %q", ref.Text, want)
+	}
+	if len(ref.Turns) != 0 {
+		t.Errorf("unexpected speaker turns %+v", ref.Turns)
+	}
+}
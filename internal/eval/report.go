@@ -0,0 +1,139 @@
+package eval
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Результат сравнения одной транскрибации с эталоном
+type Report struct {
+	Name        string             `json:"name"`
+	Reference   string             `json:"reference,omitempty"`
+	WER         float64            `json:"wer"`
+	CER         float64            `json:"cer"`
+	Words       Counts             `json:"words"`
+	Chars       Counts             `json:"chars"`
+	DER         *float64           `json:"der,omitempty"`
+	Diarization *DiarizationCounts `json:"diarization,omitempty"`
+}
+
+// Сравнить результат с эталоном. DER считается, только если в эталоне есть реплики спикеров
+func Evaluate(res *prerecorderv2.Result, ref *Reference, n Normalization) Report {
+	var report Report
+
+	if ref.Text != "" {
+		refWords := Normalize(ref.Text, n)
+		hypWords := Normalize(HypothesisText(res), n)
+		ops := Align(refWords, hypWords)
+
+		report.Words = Count(ops)
+		report.Chars = CharCounts(refWords, hypWords, ops)
+		report.WER = report.Words.Rate()
+		report.CER = report.Chars.Rate()
+	}
+
+	if len(ref.Turns) > 0 {
+		counts := Diarization(ref.Turns, HypothesisTurns(res))
+		der := counts.Rate()
+		report.Diarization, report.DER = &counts, &der
+	}
+	return report
+}
+
+// Итог по пакету: ошибки суммируются, а не усредняются, поэтому длинные файлы весят больше
+func Total(reports []Report) Report {
+	total := Report{Name: "TOTAL"}
+	var diarization DiarizationCounts
+	hasDiarization := false
+
+	for _, r := range reports {
+		total.Words.Add(r.Words)
+		total.Chars.Add(r.Chars)
+		if r.Diarization != nil {
+			diarization.Add(*r.Diarization)
+			hasDiarization = true
+		}
+	}
+	total.WER = total.Words.Rate()
+	total.CER = total.Chars.Rate()
+	if hasDiarization {
+		der := diarization.Rate()
+		total.Diarization, total.DER = &diarization, &der
+	}
+	return total
+}
+
+// Текст гипотезы: высказывания по порядку или полный транскрипт
+func HypothesisText(res *prerecorderv2.Result) string {
+	if len(res.Transcription.Utterances) == 0 {
+		return res.Transcription.FullTranscript
+	}
+	parts := make([]string, 0, len(res.Transcription.Utterances))
+	for _, u := range res.Transcription.Utterances {
+		parts = append(parts, u.Text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Реплики гипотезы, высказывания без спикера считаются одним неизвестным спикером
+func HypothesisTurns(res *prerecorderv2.Result) []SpeakerTurn {
+	turns := make([]SpeakerTurn, 0, len(res.Transcription.Utterances))
+	for _, u := range res.Transcription.Utterances {
+		speaker := "unknown"
+		if u.Speaker != nil {
+			speaker = strconv.Itoa(*u.Speaker)
+		}
+		turns = append(turns, SpeakerTurn{Speaker: speaker, Start: u.Start, End: u.End})
+	}
+	return turns
+}
+
+// JSON: отчеты по файлам и итог
+func WriteJSON(w io.Writer, reports []Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Files []Report `json:"files"`
+		Total Report   `json:"total"`
+	}{Files: reports, Total: Total(reports)})
+}
+
+// CSV: строка на файл и итоговая строка TOTAL
+func WriteCSV(w io.Writer, reports []Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"name", "reference", "wer", "cer", "ref_words", "hits", "substitutions", "insertions", "deletions",
+		"der", "speech", "missed", "false_alarm", "confusion",
+	})
+
+	for _, r := range append(reports, Total(reports)) {
+		row := []string{
+			r.Name, r.Reference, rate(r.WER), rate(r.CER),
+			strconv.Itoa(r.Words.RefLength()), strconv.Itoa(r.Words.Hits),
+			strconv.Itoa(r.Words.Substitutions), strconv.Itoa(r.Words.Insertions), strconv.Itoa(r.Words.Deletions),
+			"", "", "", "", "",
+		}
+		if r.Diarization != nil {
+			d := r.Diarization
+			row[9], row[10], row[11], row[12], row[13] = rate(*r.DER), seconds(d.Speech), seconds(d.Missed), seconds(d.FalseAlarm), seconds(d.Confusion)
+		}
+		cw.Write(row)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func rate(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+func seconds(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
@@ -0,0 +1,52 @@
+package extraction
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type classesFile struct {
+	Classes []string `yaml:"classes"`
+}
+
+/*
+# Загрузить классы для извлечения структурированных данных из yaml файла
+
+	classes:
+	  - order number
+	  - product name
+	  - outcome
+*/
+func LoadClasses(filePath string) ([]string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed open extraction classes: %w", filePath, err)
+	}
+
+	var file classesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: failed parse extraction classes: %w", filePath, err)
+	}
+
+	seen := map[string]bool{}
+	classes := make([]string, 0, len(file.Classes))
+	for _, class := range file.Classes {
+		class = strings.TrimSpace(class)
+		if class == "" {
+			return nil, fmt.Errorf("%s: empty extraction class", filePath)
+		}
+		if seen[strings.ToLower(class)] {
+			return nil, fmt.Errorf("%s: duplicate extraction class %q", filePath, class)
+		}
+		seen[strings.ToLower(class)] = true
+		classes = append(classes, class)
+	}
+
+	if len(classes) == 0 {
+		return nil, fmt.Errorf("%s: no extraction classes", filePath)
+	}
+	return classes, nil
+}
@@ -0,0 +1,134 @@
+package extraction
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Разделитель нескольких значений одного класса в ячейке
+const valuesSeparator = "; "
+
+// Строка с извлеченными данными одной задачи
+type Record struct {
+	TaskID   string              `json:"task_id"`
+	FileName string              `json:"filename"`
+	Values   map[string][]string `json:"values"` // класс -> значения
+}
+
+func NewRecord(resp *prerecorderv2.PreRecorderResultResponse, classes []string) Record {
+	rec := Record{
+		TaskID: resp.ID,
+		Values: map[string][]string{},
+	}
+	if resp.File != nil {
+		rec.FileName = resp.File.Filename
+	}
+	if resp.Result != nil {
+		for _, class := range classes {
+			rec.Values[class] = resp.Result.StructuredDataExtraction.Results.Values(class)
+		}
+	}
+	return rec
+}
+
+/*
+# Проверить файл с извлеченными данными до запуска задачи
+
+Расширение должно быть .csv или .jsonl. Заголовок существующего CSV должен
+совпадать с текущими классами, иначе новые строки сдвинут колонки.
+*/
+func CheckOutput(filePath string, classes []string) error {
+	switch ext := strings.ToLower(filepath.Ext(filePath)); ext {
+	case ".csv":
+		return checkHeader(filePath, csvHeader(classes))
+	case ".jsonl", ".json":
+		return nil
+	default:
+		return fmt.Errorf("%s: unsupported extraction output format %q, use .csv or .jsonl", filePath, ext)
+	}
+}
+
+func csvHeader(classes []string) []string {
+	return append([]string{"task_id", "filename"}, classes...)
+}
+
+// Заголовок существующего непустого CSV совпадает с want
+func checkHeader(filePath string, want []string) error {
+	file, err := os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%s: failed open extraction output: %w", filePath, err)
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+	got, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("%s: failed read extraction output header: %w", filePath, err)
+	}
+	if !slices.Equal(got, want) {
+		return fmt.Errorf("%s: header %q does not match the extraction classes %q, use another output file",
+			filePath, strings.Join(got, ","), strings.Join(want, ","))
+	}
+	return nil
+}
+
+/*
+# Дописать строку в файл с извлеченными данными
+
+Формат определяется по расширению: .csv или .jsonl. Заголовок CSV пишется
+только в новый файл, поэтому при пакетной обработке все задачи
+попадают в одну таблицу. Формат и заголовок проверяются через CheckOutput
+до открытия файла.
+*/
+func AppendFile(filePath string, classes []string, rec Record) error {
+	if err := CheckOutput(filePath, classes); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("%s: failed open extraction output: %w", filePath, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".csv":
+		w := csv.NewWriter(file)
+		if info.Size() == 0 {
+			if err := w.Write(csvHeader(classes)); err != nil {
+				return err
+			}
+		}
+
+		row := []string{rec.TaskID, rec.FileName}
+		for _, class := range classes {
+			row = append(row, strings.Join(rec.Values[class], valuesSeparator))
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		return json.NewEncoder(file).Encode(rec)
+	}
+}
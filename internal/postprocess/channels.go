@@ -0,0 +1,97 @@
+package postprocess
+
+import (
+	"sort"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+/*
+# Объединить каналы одной записи
+
+Результаты моно файлов, полученных разделением многоканальной записи,
+идут параллельно во времени: номер результата становится каналом
+высказываний, а высказывания всех каналов чередуются по времени начала.
+Переводы объединяются по языку, фрагменты тональности получают канал
+своего результата. Спикеры диаризации внутри канала сохраняются как есть,
+подписи по каналам задаются --channel-names.
+*/
+func Interleave(results []*prerecorderv2.Result) *prerecorderv2.Result {
+	merged := &prerecorderv2.Result{}
+	translations := map[string]int{}
+	languages := map[string]bool{}
+
+	for channel, res := range results {
+		if res == nil {
+			continue
+		}
+
+		merged.Transcription.Utterances = append(merged.Transcription.Utterances, onChannel(res.Transcription.Utterances, channel)...)
+		merged.Diarization.Results = append(merged.Diarization.Results, onChannel(res.Diarization.Results, channel)...)
+		for _, lang := range res.Transcription.Languages {
+			if !languages[lang] {
+				languages[lang] = true
+				merged.Transcription.Languages = append(merged.Transcription.Languages, lang)
+			}
+		}
+
+		for _, t := range res.Translation.Results {
+			key := strings.Join(t.Languages, ",")
+			idx, ok := translations[key]
+			if !ok {
+				idx = len(merged.Translation.Results)
+				translations[key] = idx
+				merged.Translation.Results = append(merged.Translation.Results, prerecorderv2.Transcription{Languages: t.Languages})
+			}
+			dst := &merged.Translation.Results[idx]
+			dst.Utterances = append(dst.Utterances, onChannel(t.Utterances, channel)...)
+		}
+
+		for _, s := range res.SentimentAnalysis.Results {
+			s.Channel = channel
+			merged.SentimentAnalysis.Results = append(merged.SentimentAnalysis.Results, s)
+		}
+
+		merged.Metadata.AudioDuration = max(merged.Metadata.AudioDuration, Duration(res))
+		merged.Metadata.BillingTime += res.Metadata.BillingTime
+		merged.Metadata.TranscriptionTime += res.Metadata.TranscriptionTime
+	}
+	merged.Metadata.NumberOfDistinctChannels = len(results)
+
+	sortUtterances(&merged.Transcription)
+	for i := range merged.Translation.Results {
+		sortUtterances(&merged.Translation.Results[i])
+	}
+	sort.SliceStable(merged.Diarization.Results, func(i, j int) bool {
+		return merged.Diarization.Results[i].Start < merged.Diarization.Results[j].Start
+	})
+	sort.SliceStable(merged.SentimentAnalysis.Results, func(i, j int) bool {
+		return merged.SentimentAnalysis.Results[i].Start < merged.SentimentAnalysis.Results[j].Start
+	})
+
+	merged.Translation.Success = len(merged.Translation.Results) > 0
+	merged.Diarization.Success = len(merged.Diarization.Results) > 0
+	merged.SentimentAnalysis.Success = len(merged.SentimentAnalysis.Results) > 0
+	return merged
+}
+
+func onChannel(utterances []prerecorderv2.Utterance, channel int) []prerecorderv2.Utterance {
+	out := make([]prerecorderv2.Utterance, len(utterances))
+	for i, u := range utterances {
+		u.Channel = channel
+		out[i] = u
+	}
+	return out
+}
+
+// Упорядочить высказывания по времени и собрать полный текст в том же порядке
+func sortUtterances(t *prerecorderv2.Transcription) {
+	sort.SliceStable(t.Utterances, func(i, j int) bool { return t.Utterances[i].Start < t.Utterances[j].Start })
+
+	parts := make([]string, 0, len(t.Utterances))
+	for _, u := range t.Utterances {
+		parts = append(parts, strings.TrimSpace(u.Text))
+	}
+	t.FullTranscript = strings.Join(parts, " ")
+}
@@ -0,0 +1,194 @@
+package postprocess
+
+import (
+	"fmt"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Как сопоставлять спикеров разных частей при объединении
+const (
+	SpeakersSame     = "same"     // одинаковый номер в разных частях - один и тот же человек
+	SpeakersSeparate = "separate" // спикеры каждой части - разные люди
+)
+
+// Часть объединяемой записи
+type Part struct {
+	Result   *prerecorderv2.Result
+	Speakers map[int]int // явное переназначение номеров спикеров части
+}
+
+// Длительность части: из метаданных или по концу последнего высказывания
+func Duration(res *prerecorderv2.Result) float64 {
+	if res.Metadata.AudioDuration > 0 {
+		return res.Metadata.AudioDuration
+	}
+	end := 0.0
+	for _, u := range res.Transcription.Utterances {
+		end = max(end, u.End)
+	}
+	return end
+}
+
+/*
+# Объединить части записи в одну шкалу времени
+
+Каждая часть сдвигается на суммарную длительность предыдущих. Номера
+спикеров сначала переназначаются по Part.Speakers, в режиме separate
+сдвигаются, чтобы не пересекаться с предыдущими частями, и в конце
+перенумеровываются по порядку появления, поэтому в результате они идут
+подряд с нуля. Переводы объединяются по языку, главы и фрагменты
+тональности сдвигаются вместе с частью. Остальные результаты анализа относятся к отдельной части и
+в объединенный результат не попадают.
+*/
+func Merge(parts []Part, mode string) (*prerecorderv2.Result, error) {
+	switch mode {
+	case "", SpeakersSame, SpeakersSeparate:
+	default:
+		return nil, fmt.Errorf("unknown speakers mode %q, available: %s, %s", mode, SpeakersSame, SpeakersSeparate)
+	}
+
+	merged := &prerecorderv2.Result{}
+	translations := map[string]int{}
+	languages := map[string]bool{}
+	var transcripts []string
+	offset := 0.0
+	speakerBase := 0
+
+	for _, part := range parts {
+		res := part.Result
+		if res == nil {
+			continue
+		}
+
+		duration := Duration(res)
+		partMax := -1
+		mapSpeaker := func(speaker *int) *int {
+			if speaker == nil {
+				return nil
+			}
+			id := *speaker
+			if to, ok := part.Speakers[id]; ok {
+				id = to
+			}
+			partMax = max(partMax, id)
+			if mode == SpeakersSeparate {
+				id += speakerBase
+			}
+			return &id
+		}
+
+		Shift(res, offset)
+		for _, u := range res.Transcription.Utterances {
+			u.Speaker = mapSpeaker(u.Speaker)
+			merged.Transcription.Utterances = append(merged.Transcription.Utterances, u)
+		}
+		for _, u := range res.Diarization.Results {
+			u.Speaker = mapSpeaker(u.Speaker)
+			merged.Diarization.Results = append(merged.Diarization.Results, u)
+		}
+		if text := strings.TrimSpace(res.Transcription.FullTranscript); text != "" {
+			transcripts = append(transcripts, text)
+		}
+		for _, lang := range res.Transcription.Languages {
+			if !languages[lang] {
+				languages[lang] = true
+				merged.Transcription.Languages = append(merged.Transcription.Languages, lang)
+			}
+		}
+
+		for _, t := range res.Translation.Results {
+			key := strings.Join(t.Languages, ",")
+			idx, ok := translations[key]
+			if !ok {
+				idx = len(merged.Translation.Results)
+				translations[key] = idx
+				merged.Translation.Results = append(merged.Translation.Results, prerecorderv2.Transcription{Languages: t.Languages})
+			}
+			dst := &merged.Translation.Results[idx]
+			for _, u := range t.Utterances {
+				u.Speaker = mapSpeaker(u.Speaker)
+				dst.Utterances = append(dst.Utterances, u)
+			}
+			dst.FullTranscript = strings.TrimSpace(dst.FullTranscript + " " + t.FullTranscript)
+		}
+
+		merged.Chapterization.Results = append(merged.Chapterization.Results, res.Chapterization.Results...)
+		for _, s := range res.SentimentAnalysis.Results {
+			s.Speaker = mapSpeaker(s.Speaker)
+			merged.SentimentAnalysis.Results = append(merged.SentimentAnalysis.Results, s)
+		}
+
+		merged.Metadata.AudioDuration += duration
+		merged.Metadata.BillingTime += res.Metadata.BillingTime
+		merged.Metadata.TranscriptionTime += res.Metadata.TranscriptionTime
+		merged.Metadata.NumberOfDistinctChannels = max(merged.Metadata.NumberOfDistinctChannels, res.Metadata.NumberOfDistinctChannels)
+
+		offset += duration
+		if mode == SpeakersSeparate {
+			speakerBase += partMax + 1
+		}
+	}
+
+	merged.Transcription.FullTranscript = strings.Join(transcripts, " ")
+	merged.Translation.Success = len(merged.Translation.Results) > 0
+	merged.Chapterization.Success = len(merged.Chapterization.Results) > 0
+	merged.Diarization.Success = len(merged.Diarization.Results) > 0
+	merged.SentimentAnalysis.Success = len(merged.SentimentAnalysis.Results) > 0
+
+	renumberSpeakers(merged)
+	return merged, nil
+}
+
+// Перенумеровать спикеров по порядку появления в транскрипции
+func renumberSpeakers(res *prerecorderv2.Result) {
+	ids := map[int]int{}
+	renumber := func(utterances []prerecorderv2.Utterance) {
+		for i := range utterances {
+			speaker := utterances[i].Speaker
+			if speaker == nil {
+				continue
+			}
+			id, ok := ids[*speaker]
+			if !ok {
+				id = len(ids)
+				ids[*speaker] = id
+			}
+			utterances[i].Speaker = &id
+		}
+	}
+
+	renumber(res.Transcription.Utterances)
+	renumber(res.Diarization.Results)
+	for i := range res.Translation.Results {
+		renumber(res.Translation.Results[i].Utterances)
+	}
+	for i := range res.SentimentAnalysis.Results {
+		if speaker := res.SentimentAnalysis.Results[i].Speaker; speaker != nil {
+			if id, ok := ids[*speaker]; ok {
+				res.SentimentAnalysis.Results[i].Speaker = &id
+			}
+		}
+	}
+}
+
+// Разобрать переназначение спикеров части: "1=0,2=1" (номера с нуля, как в ответе API)
+func ParseSpeakerMap(value string) (map[int]int, error) {
+	mapping := map[int]int{}
+	for _, pair := range strings.Split(value, ",") {
+		from, to, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("bad speaker mapping %q, expected FROM=TO", pair)
+		}
+		var f, t int
+		if _, err := fmt.Sscan(from, &f); err != nil {
+			return nil, fmt.Errorf("bad speaker mapping %q: %w", pair, err)
+		}
+		if _, err := fmt.Sscan(to, &t); err != nil {
+			return nil, fmt.Errorf("bad speaker mapping %q: %w", pair, err)
+		}
+		mapping[f] = t
+	}
+	return mapping, nil
+}
@@ -0,0 +1,196 @@
+package postprocess
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Тип персональных данных, доступный для скрытия
+type PIIKind string
+
+const (
+	PIIPerson PIIKind = "person"
+	PIIPhone  PIIKind = "phone"
+	PIIEmail  PIIKind = "email"
+	PIICard   PIIKind = "card"
+)
+
+var AllPIIKinds = []PIIKind{PIIPerson, PIIPhone, PIIEmail, PIICard}
+
+// Соответствие типов сущностей NER видам персональных данных
+var entityKinds = map[string]PIIKind{
+	"PERSON":             PIIPerson,
+	"NAME":               PIIPerson,
+	"NAME_GIVEN":         PIIPerson,
+	"NAME_FAMILY":        PIIPerson,
+	"PHONE_NUMBER":       PIIPhone,
+	"PHONE":              PIIPhone,
+	"EMAIL":              PIIEmail,
+	"EMAIL_ADDRESS":      PIIEmail,
+	"CREDIT_CARD":        PIICard,
+	"CREDIT_CARD_NUMBER": PIICard,
+}
+
+var (
+	emailRe = regexp.MustCompile(`[\w.+-]+@[\w-]+(\.[\w-]+)+`)
+	phoneRe = regexp.MustCompile(`\+?\d[\d\s().-]{8,}\d`)
+	cardRe  = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+)
+
+// Разобрать список типов из флага --redact. "all" включает все типы
+func ParsePIIKinds(values []string) ([]PIIKind, error) {
+	var kinds []PIIKind
+	for _, v := range values {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == "all" {
+			return AllPIIKinds, nil
+		}
+
+		kind := PIIKind(v)
+		switch kind {
+		case PIIPerson, PIIPhone, PIIEmail, PIICard:
+			kinds = append(kinds, kind)
+		default:
+			return nil, fmt.Errorf("unknown redaction type %q, available: person, phone, email, card, all", v)
+		}
+	}
+	return kinds, nil
+}
+
+type redactor struct {
+	kinds    map[PIIKind]bool
+	entities []prerecorderv2.Entity // найденные NER сущности выбранных типов, от длинных к коротким
+	masks    []spellingRule         // замены текста сущностей целыми словами, в том же порядке
+}
+
+// Скрыть персональные данные в транскрибации, переводах, субтитрах и фрагментах тональности.
+// Имена скрываются только по результатам NER, телефоны, почта и номера карт дополнительно ищутся локально
+func Redact(res *prerecorderv2.Result, kinds []PIIKind) {
+	if res == nil || len(kinds) == 0 {
+		return
+	}
+
+	r := &redactor{kinds: map[PIIKind]bool{}}
+	for _, kind := range kinds {
+		r.kinds[kind] = true
+	}
+
+	for _, entity := range res.NamedEntityRecognition.Entity {
+		if kind, ok := entityKinds[strings.ToUpper(entity.Type)]; ok && r.kinds[kind] && entity.Text != "" {
+			r.entities = append(r.entities, entity)
+		}
+	}
+	sort.Slice(r.entities, func(i, j int) bool {
+		return len(r.entities[i].Text) > len(r.entities[j].Text)
+	})
+	for _, entity := range r.entities {
+		r.masks = append(r.masks, spellingRule{
+			re:        regexp.MustCompile(`(?i)` + regexp.QuoteMeta(entity.Text)),
+			canonical: mask(entityKinds[strings.ToUpper(entity.Type)]),
+		})
+	}
+
+	r.transcription(&res.Transcription)
+	for i := range res.Translation.Results {
+		r.transcription(&res.Translation.Results[i])
+	}
+	for i := range res.Diarization.Results {
+		r.utterance(&res.Diarization.Results[i])
+	}
+	for i := range res.SentimentAnalysis.Results {
+		res.SentimentAnalysis.Results[i].Text = r.text(res.SentimentAnalysis.Results[i].Text)
+	}
+	for i := range res.NamedEntityRecognition.Entity {
+		entity := &res.NamedEntityRecognition.Entity[i]
+		if kind, ok := entityKinds[strings.ToUpper(entity.Type)]; ok && r.kinds[kind] {
+			entity.Text = mask(kind)
+		}
+	}
+}
+
+func (r *redactor) transcription(t *prerecorderv2.Transcription) {
+	t.FullTranscript = r.text(t.FullTranscript)
+	for i := range t.Utterances {
+		r.utterance(&t.Utterances[i])
+	}
+	for i := range t.Subtitles {
+		t.Subtitles[i].Subtitles = r.text(t.Subtitles[i].Subtitles)
+	}
+}
+
+func (r *redactor) utterance(u *prerecorderv2.Utterance) {
+	u.Text = r.text(u.Text)
+	for i := range u.Words {
+		w := &u.Words[i]
+		if kind, ok := r.wordEntity(w); ok {
+			w.Word = mask(kind)
+			continue
+		}
+		w.Word = r.text(w.Word)
+	}
+}
+
+// Слово целиком попадает во временной интервал одной из сущностей
+func (r *redactor) wordEntity(w *prerecorderv2.Word) (PIIKind, bool) {
+	for _, entity := range r.entities {
+		if entity.End > entity.Start && w.Start >= entity.Start && w.End <= entity.End {
+			return entityKinds[strings.ToUpper(entity.Type)], true
+		}
+	}
+	return "", false
+}
+
+func (r *redactor) text(s string) string {
+	// сущность "Ann" не должна задевать "Announcement"
+	for _, rule := range r.masks {
+		s = rule.replace(s)
+	}
+
+	if r.kinds[PIIEmail] {
+		s = emailRe.ReplaceAllString(s, mask(PIIEmail))
+	}
+	if r.kinds[PIICard] {
+		s = cardRe.ReplaceAllStringFunc(s, func(m string) string {
+			if luhnValid(m) {
+				return mask(PIICard)
+			}
+			return m
+		})
+	}
+	if r.kinds[PIIPhone] {
+		s = phoneRe.ReplaceAllString(s, mask(PIIPhone))
+	}
+
+	return s
+}
+
+func mask(kind PIIKind) string {
+	return "[" + strings.ToUpper(string(kind)) + "]"
+}
+
+// Проверка номера карты по алгоритму Луна, чтобы не скрывать произвольные длинные числа
+func luhnValid(number string) bool {
+	var digits []int
+	for _, c := range number {
+		if c >= '0' && c <= '9' {
+			digits = append(digits, int(c-'0'))
+		}
+	}
+
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := digits[i]
+		if (len(digits)-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return len(digits) >= 13 && sum%10 == 0
+}
@@ -0,0 +1,261 @@
+package postprocess
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Отрезок времени в секундах
+type TimeRange struct {
+	Start float64
+	End   float64
+}
+
+// Разобрать время: секунды (90, -2.5) или таймкод (1:30, 00:01:30.5)
+func ParseSeconds(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	sign := 1.0
+	if strings.HasPrefix(value, "-") {
+		sign, value = -1, value[1:]
+	}
+
+	parts := strings.Split(strings.Replace(value, ",", ".", 1), ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("bad time %q", value)
+	}
+	total := 0.0
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("bad time %q", value)
+		}
+		total = total*60 + v
+	}
+	return sign * total, nil
+}
+
+// Разобрать отрезок START-END, конец можно опустить: 1:00- означает до конца записи
+func ParseTimeRange(value string) (TimeRange, error) {
+	from, to, ok := strings.Cut(value, "-")
+	if !ok {
+		return TimeRange{}, fmt.Errorf("bad range %q, expected START-END", value)
+	}
+
+	var r TimeRange
+	var err error
+	if r.Start, err = ParseSeconds(from); err != nil {
+		return TimeRange{}, err
+	}
+	r.End = math.Inf(1)
+	if strings.TrimSpace(to) != "" {
+		if r.End, err = ParseSeconds(to); err != nil {
+			return TimeRange{}, err
+		}
+	}
+	if r.Start < 0 || r.End <= r.Start {
+		return TimeRange{}, fmt.Errorf("bad range %q, end must be after start", value)
+	}
+	return r, nil
+}
+
+// Преобразование шкалы времени: какие отрезки остаются и куда переходят моменты времени
+type timeline interface {
+	keep(start, end float64) bool
+	at(t float64) float64
+}
+
+type shift float64
+
+func (s shift) keep(_, end float64) bool { return end+float64(s) > 0 }
+func (s shift) at(t float64) float64     { return max(0, t+float64(s)) }
+
+type scale float64
+
+func (s scale) keep(_, _ float64) bool { return true }
+func (s scale) at(t float64) float64   { return t * float64(s) }
+
+// Вырезанные отрезки, отсортированные и без пересечений
+type cut []TimeRange
+
+// Слово остается, если его середина не попала в вырезанный отрезок
+func (c cut) keep(start, end float64) bool {
+	mid := (start + end) / 2
+	for _, r := range c {
+		if mid >= r.Start && mid < r.End {
+			return false
+		}
+	}
+	return true
+}
+
+// Время сдвигается на длину вырезанного до него, момент внутри вырезанного отрезка прижимается к его началу
+func (c cut) at(t float64) float64 {
+	removed := 0.0
+	for _, r := range c {
+		if t < r.Start {
+			break
+		}
+		if t < r.End {
+			return r.Start - removed
+		}
+		removed += r.End - r.Start
+	}
+	return t - removed
+}
+
+func newCut(ranges []TimeRange) cut {
+	sorted := append([]TimeRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	var merged cut
+	for _, r := range sorted {
+		if n := len(merged); n > 0 && r.Start <= merged[n-1].End {
+			merged[n-1].End = max(merged[n-1].End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// Сдвинуть все таймкоды на offset секунд, при отрицательном сдвиге речь до нуля отбрасывается
+func Shift(res *prerecorderv2.Result, offset float64) {
+	if offset != 0 {
+		retime(res, shift(offset))
+	}
+}
+
+// Умножить таймкоды на factor, например 1/1.25 для записи, ускоренной в 1.25 раза
+func Scale(res *prerecorderv2.Result, factor float64) {
+	if factor != 1 {
+		retime(res, scale(factor))
+	}
+}
+
+// Вырезать отрезки из записи: речь внутри удаляется, последующие таймкоды сдвигаются назад
+func Cut(res *prerecorderv2.Result, ranges []TimeRange) {
+	if len(ranges) > 0 {
+		retime(res, newCut(ranges))
+	}
+}
+
+// Отрезки вокруг r: вырезать их - значит оставить только r
+func Outside(r TimeRange) []TimeRange {
+	var ranges []TimeRange
+	if r.Start > 0 {
+		ranges = append(ranges, TimeRange{Start: 0, End: r.Start})
+	}
+	if !math.IsInf(r.End, 1) {
+		ranges = append(ranges, TimeRange{Start: r.End, End: math.Inf(1)})
+	}
+	return ranges
+}
+
+/*
+# Применить преобразование времени к результату
+
+Меняются высказывания и слова транскрипции, переводов и диаризации,
+фрагменты тональности, главы и длительность записи. Готовые субтитры из ответа API удаляются:
+их таймкоды больше не соответствуют записи, субтитры строятся заново
+форматами вывода.
+*/
+func retime(res *prerecorderv2.Result, tl timeline) {
+	if res == nil {
+		return
+	}
+
+	retimeTranscription(&res.Transcription, tl)
+	for i := range res.Translation.Results {
+		retimeTranscription(&res.Translation.Results[i], tl)
+	}
+	res.Diarization.Results, _ = retimeUtterances(res.Diarization.Results, tl)
+
+	sentiments := res.SentimentAnalysis.Results[:0]
+	for _, s := range res.SentimentAnalysis.Results {
+		if tl.keep(s.Start, s.End) {
+			s.Start, s.End = tl.at(s.Start), tl.at(s.End)
+			sentiments = append(sentiments, s)
+		}
+	}
+	res.SentimentAnalysis.Results = sentiments
+
+	chapters := res.Chapterization.Results[:0]
+	for _, ch := range res.Chapterization.Results {
+		// глава пропадает, если вырезана целиком
+		if start, end := tl.at(ch.Start), tl.at(ch.End); end > start {
+			ch.Start, ch.End = start, end
+			chapters = append(chapters, ch)
+		}
+	}
+	res.Chapterization.Results = chapters
+
+	if res.Metadata.AudioDuration > 0 {
+		res.Metadata.AudioDuration = tl.at(res.Metadata.AudioDuration)
+	}
+}
+
+func retimeTranscription(t *prerecorderv2.Transcription, tl timeline) {
+	var changed bool
+	t.Utterances, changed = retimeUtterances(t.Utterances, tl)
+	t.Subtitles = nil
+
+	// полный текст собирается заново, только если часть речи была вырезана
+	if changed {
+		parts := make([]string, 0, len(t.Utterances))
+		for _, u := range t.Utterances {
+			parts = append(parts, strings.TrimSpace(u.Text))
+		}
+		t.FullTranscript = strings.Join(parts, " ")
+	}
+}
+
+// Высказывания после преобразования; changed - была ли удалена часть речи
+func retimeUtterances(utterances []prerecorderv2.Utterance, tl timeline) ([]prerecorderv2.Utterance, bool) {
+	out := utterances[:0]
+	changed := false
+
+	for _, u := range utterances {
+		if len(u.Words) == 0 {
+			if !tl.keep(u.Start, u.End) {
+				changed = true
+				continue
+			}
+			u.Start, u.End = tl.at(u.Start), tl.at(u.End)
+			out = append(out, u)
+			continue
+		}
+
+		words := make([]prerecorderv2.Word, 0, len(u.Words))
+		for _, w := range u.Words {
+			if !tl.keep(w.Start, w.End) {
+				continue
+			}
+			w.Start, w.End = tl.at(w.Start), tl.at(w.End)
+			words = append(words, w)
+		}
+
+		switch {
+		case len(words) == 0:
+			changed = true
+			continue
+		case len(words) < len(u.Words):
+			changed = true
+			text := make([]string, len(words))
+			for i, w := range words {
+				text[i] = strings.TrimSpace(w.Word)
+			}
+			u.Text = strings.Join(text, " ")
+			u.Start, u.End = words[0].Start, words[len(words)-1].End
+		default:
+			u.Start, u.End = tl.at(u.Start), tl.at(u.End)
+		}
+		u.Words = words
+		out = append(out, u)
+	}
+	return out, changed
+}
@@ -0,0 +1,100 @@
+package postprocess
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+	"go-gladia.io-client/internal/dictionary"
+)
+
+type spellingRule struct {
+	re        *regexp.Regexp
+	canonical string
+}
+
+// Локально исправить написание по словарю, если серверная функция custom_spelling выключена.
+// Варианты ищутся целыми словами без учета регистра
+func ApplySpelling(res *prerecorderv2.Result, spelling dictionary.Spelling) {
+	if res == nil || len(spelling) == 0 {
+		return
+	}
+
+	rules := spellingRules(spelling)
+	apply := func(s string) string {
+		for _, rule := range rules {
+			s = rule.replace(s)
+		}
+		return s
+	}
+
+	t := &res.Transcription
+	t.FullTranscript = apply(t.FullTranscript)
+	for i := range t.Utterances {
+		u := &t.Utterances[i]
+		u.Text = apply(u.Text)
+		for j := range u.Words {
+			u.Words[j].Word = apply(u.Words[j].Word)
+		}
+	}
+	for i := range t.Subtitles {
+		t.Subtitles[i].Subtitles = apply(t.Subtitles[i].Subtitles)
+	}
+}
+
+// Правила от длинных вариантов к коротким, чтобы "glad ia" заменялось раньше "glad"
+func spellingRules(spelling dictionary.Spelling) []spellingRule {
+	type pair struct{ variant, canonical string }
+
+	var pairs []pair
+	for canonical, variants := range spelling {
+		for _, variant := range variants {
+			pairs = append(pairs, pair{strings.TrimSpace(variant), canonical})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if len(pairs[i].variant) != len(pairs[j].variant) {
+			return len(pairs[i].variant) > len(pairs[j].variant)
+		}
+		return pairs[i].variant < pairs[j].variant
+	})
+
+	rules := make([]spellingRule, 0, len(pairs))
+	for _, p := range pairs {
+		rules = append(rules, spellingRule{
+			re:        regexp.MustCompile(`(?i)` + regexp.QuoteMeta(p.variant)),
+			canonical: p.canonical,
+		})
+	}
+	return rules
+}
+
+// Заменить вхождения, которые не являются частью другого слова
+func (r spellingRule) replace(s string) string {
+	var b strings.Builder
+	last := 0
+
+	for _, loc := range r.re.FindAllStringIndex(s, -1) {
+		before, _ := utf8.DecodeLastRuneInString(s[:loc[0]])
+		after, _ := utf8.DecodeRuneInString(s[loc[1]:])
+		if isWordRune(before) || isWordRune(after) {
+			continue
+		}
+		b.WriteString(s[last:loc[0]])
+		b.WriteString(r.canonical)
+		last = loc[1]
+	}
+
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,102 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+var ErrNotFound = errors.New("task not found in local history")
+
+// Локальная история задач: каждый результат хранится отдельным json файлом <task_id>.json
+type FilesRepo struct {
+	dir string
+}
+
+// Открыть историю в каталоге dir. Пустой dir - каталог по умолчанию в конфигурации пользователя
+func NewFilesRepo(dir string) (*FilesRepo, error) {
+	if dir == "" {
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed detect history dir: %w", err)
+		}
+		dir = filepath.Join(configDir, "gladia-cli", "history")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("%s: failed create history dir: %w", dir, err)
+	}
+
+	return &FilesRepo{dir: dir}, nil
+}
+
+// Сохранить результат задачи, существующая запись перезаписывается
+func (r *FilesRepo) Save(resp *prerecorderv2.PreRecorderResultResponse) error {
+	if resp == nil || resp.ID == "" {
+		return errors.New("failed save task: empty task id")
+	}
+
+	data, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// запись через временный файл, чтобы не оставить битый json при падении
+	tmp := r.path(resp.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("%s: failed save task: %w", resp.ID, err)
+	}
+	return os.Rename(tmp, r.path(resp.ID))
+}
+
+// Получить результат задачи по id
+func (r *FilesRepo) Get(taskID string) (*prerecorderv2.PreRecorderResultResponse, error) {
+	data, err := os.ReadFile(r.path(taskID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", taskID, ErrNotFound)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var resp prerecorderv2.PreRecorderResultResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("%s: failed parse task: %w", taskID, err)
+	}
+	return &resp, nil
+}
+
+// Все сохраненные задачи, от новых к старым
+func (r *FilesRepo) List() ([]*prerecorderv2.PreRecorderResultResponse, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*prerecorderv2.PreRecorderResultResponse
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		resp, err := r.Get(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, resp)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt > list[j].CreatedAt
+	})
+	return list, nil
+}
+
+func (r *FilesRepo) path(taskID string) string {
+	// id приходит извне (из callback), поэтому не допускаем выход за пределы каталога
+	return filepath.Join(r.dir, filepath.Base(filepath.Clean("/"+taskID))+".json")
+}
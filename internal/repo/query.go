@@ -0,0 +1,102 @@
+package repo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Фильтр задач по статусу, пользовательским метаданным и дате создания
+type Query struct {
+	Status string            // статус задачи, пустой - любой
+	Where  map[string]string // все пары должны совпасть со значениями custom_metadata
+	Limit  int               // 0 - без ограничения
+	Since  time.Time         // только задачи, созданные не раньше, нулевое - без ограничения
+}
+
+// Синонимы статусов для флага --filter
+var statusAliases = map[string][]string{
+	"completed":   {"done"},
+	"in progress": {"queued", "processing"},
+	"in_progress": {"queued", "processing"},
+}
+
+// Разобрать пары key=value из флагов --meta и --where
+func ParseTags(values []string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid tag %q, expected key=value", value)
+		}
+		tags[key] = strings.TrimSpace(val)
+	}
+	return tags, nil
+}
+
+func (q Query) Match(status string, customMeta any) bool {
+	if q.Status != "" && !q.matchStatus(status) {
+		return false
+	}
+
+	if len(q.Where) == 0 {
+		return true
+	}
+
+	meta, ok := customMeta.(map[string]any)
+	if !ok {
+		return false
+	}
+	for key, want := range q.Where {
+		got, ok := meta[key]
+		if !ok || fmt.Sprint(got) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// Задача создана не раньше Since. Дата, которую не удалось разобрать, не отсекает задачу
+func (q Query) MatchCreated(createdAt string) bool {
+	if q.Since.IsZero() {
+		return true
+	}
+	created, err := time.Parse(time.RFC3339, createdAt)
+	return err != nil || !created.Before(q.Since)
+}
+
+func (q Query) matchStatus(status string) bool {
+	want := strings.ToLower(q.Status)
+	if aliases, ok := statusAliases[want]; ok {
+		for _, alias := range aliases {
+			if status == alias {
+				return true
+			}
+		}
+		return false
+	}
+	return status == want
+}
+
+// Задачи из истории, подходящие под фильтр, от новых к старым
+func (r *FilesRepo) Find(q Query) ([]*prerecorderv2.PreRecorderResultResponse, error) {
+	all, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*prerecorderv2.PreRecorderResultResponse
+	for _, resp := range all {
+		if !q.Match(resp.Status, resp.CustomMeta) || !q.MatchCreated(resp.CreatedAt) {
+			continue
+		}
+		found = append(found, resp)
+		if q.Limit > 0 && len(found) == q.Limit {
+			break
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,253 @@
+package sentiment
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Значения тональности в ответе API
+const (
+	Positive = "positive"
+	Neutral  = "neutral"
+	Negative = "negative"
+	Mixed    = "mixed"
+)
+
+// Падение средней оценки от первой трети записи к последней, при котором разговор считается эскалацией
+const EscalationDrop = 0.5
+
+// Фрагмент анализа тональности со спикером и числовой оценкой
+type Segment struct {
+	Start     float64
+	End       float64
+	Channel   int
+	Speaker   *int
+	Sentiment string
+	Emotion   string
+	Score     float64 // +1 positive, -1 negative, 0 neutral и mixed
+	Text      string
+}
+
+// Числовая оценка тональности
+func Score(sentiment string) float64 {
+	switch strings.ToLower(sentiment) {
+	case Positive:
+		return 1
+	case Negative:
+		return -1
+	}
+	return 0
+}
+
+/*
+# Фрагменты тональности результата
+
+Спикер фрагмента берется из высказывания того же канала с наибольшим
+пересечением по времени, поэтому совпадает с подписями транскрипта, в том
+числе при --channel-names. Если такого высказывания нет, остается спикер
+из ответа API. Фрагменты упорядочены по времени начала.
+*/
+func Segments(res *prerecorderv2.Result) []Segment {
+	if res == nil {
+		return nil
+	}
+
+	segments := make([]Segment, 0, len(res.SentimentAnalysis.Results))
+	for _, item := range res.SentimentAnalysis.Results {
+		seg := Segment{
+			Start:     item.Start,
+			End:       item.End,
+			Channel:   item.Channel,
+			Speaker:   item.Speaker,
+			Sentiment: strings.ToLower(item.Sentiment),
+			Emotion:   strings.ToLower(item.Emotion),
+			Score:     Score(item.Sentiment),
+			Text:      strings.TrimSpace(item.Text),
+		}
+		if u, ok := overlapping(res.Transcription.Utterances, item); ok && u.Speaker != nil {
+			seg.Speaker = u.Speaker
+		}
+		segments = append(segments, seg)
+	}
+	sort.SliceStable(segments, func(i, j int) bool { return segments[i].Start < segments[j].Start })
+	return segments
+}
+
+func overlapping(utterances []prerecorderv2.Utterance, item prerecorderv2.Sentiment) (prerecorderv2.Utterance, bool) {
+	var best prerecorderv2.Utterance
+	bestOverlap := 0.0
+	for _, u := range utterances {
+		if u.Channel != item.Channel {
+			continue
+		}
+		if overlap := min(u.End, item.End) - max(u.Start, item.Start); overlap > bestOverlap {
+			best, bestOverlap = u, overlap
+		}
+	}
+	return best, bestOverlap > 0
+}
+
+// Сводка по спикеру или по всей записи (Speaker == nil)
+type Summary struct {
+	Speaker  *int
+	Segments int
+	Duration float64
+	Counts   map[string]int // фрагментов по тональности
+	Score    float64        // средняя оценка от -1 до 1
+	Emotion  string         // преобладающая эмоция
+	First    float64        // средняя оценка в первой трети записи
+	Last     float64        // средняя оценка в последней трети записи
+	HasTrend bool
+}
+
+// Изменение оценки от начала к концу записи, отрицательное - разговор ухудшается
+func (s Summary) Trend() float64 {
+	if !s.HasTrend {
+		return 0
+	}
+	return s.Last - s.First
+}
+
+// Оценка упала к концу записи не меньше чем на EscalationDrop
+func (s Summary) Escalation() bool {
+	return s.HasTrend && s.Trend() <= -EscalationDrop
+}
+
+// Доля фрагментов с тональностью sentiment
+func (s Summary) Share(sentiment string) float64 {
+	if s.Segments == 0 {
+		return 0
+	}
+	return float64(s.Counts[sentiment]) / float64(s.Segments)
+}
+
+/*
+# Сводки по спикерам
+
+Первая сводка - вся запись, дальше спикеры по порядку номеров, фрагменты
+без спикера входят только в общую. Треть записи считается от длительности
+всей записи, поэтому тренды спикеров сравнимы между собой.
+*/
+func Summarize(segments []Segment, duration float64) []Summary {
+	for _, seg := range segments {
+		duration = max(duration, seg.End)
+	}
+
+	summaries := []Summary{summarize(nil, segments, duration)}
+
+	bySpeaker := map[int][]Segment{}
+	for _, seg := range segments {
+		if seg.Speaker != nil {
+			bySpeaker[*seg.Speaker] = append(bySpeaker[*seg.Speaker], seg)
+		}
+	}
+	speakers := make([]int, 0, len(bySpeaker))
+	for speaker := range bySpeaker {
+		speakers = append(speakers, speaker)
+	}
+	sort.Ints(speakers)
+	for _, speaker := range speakers {
+		summaries = append(summaries, summarize(&speaker, bySpeaker[speaker], duration))
+	}
+	return summaries
+}
+
+func summarize(speaker *int, segments []Segment, duration float64) Summary {
+	s := Summary{Speaker: speaker, Segments: len(segments), Counts: map[string]int{}}
+	emotions := map[string]int{}
+	var first, last []float64
+	sum := 0.0
+
+	for _, seg := range segments {
+		s.Counts[seg.Sentiment]++
+		s.Duration += seg.End - seg.Start
+		sum += seg.Score
+		if seg.Emotion != "" {
+			emotions[seg.Emotion]++
+		}
+
+		switch mid := (seg.Start + seg.End) / 2; {
+		case mid < duration/3:
+			first = append(first, seg.Score)
+		case mid >= duration*2/3:
+			last = append(last, seg.Score)
+		}
+	}
+	if s.Segments > 0 {
+		s.Score = sum / float64(s.Segments)
+	}
+	if len(first) > 0 && len(last) > 0 {
+		s.First, s.Last, s.HasTrend = mean(first), mean(last), true
+	}
+
+	// преобладающая эмоция, при равенстве - по алфавиту, чтобы отчет был воспроизводимым
+	top := 0
+	for emotion, count := range emotions {
+		if count > top || count == top && emotion < s.Emotion {
+			s.Emotion, top = emotion, count
+		}
+	}
+	return s
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// Средняя оценка в каждом из buckets равных интервалов записи, NaN - в интервале нет речи
+func Timeline(segments []Segment, duration float64, buckets int) []float64 {
+	for _, seg := range segments {
+		duration = max(duration, seg.End)
+	}
+	values := make([]float64, buckets)
+	if buckets <= 0 || duration <= 0 {
+		return values
+	}
+
+	weights := make([]float64, buckets)
+	step := duration / float64(buckets)
+	for _, seg := range segments {
+		// фрагмент учитывается во всех интервалах, которые пересекает, с весом пересечения
+		from := max(0, int(seg.Start/step))
+		to := min(buckets-1, int(seg.End/step))
+		for b := from; b <= to; b++ {
+			overlap := min(seg.End, float64(b+1)*step) - max(seg.Start, float64(b)*step)
+			if overlap <= 0 {
+				continue
+			}
+			values[b] += seg.Score * overlap
+			weights[b] += overlap
+		}
+	}
+	for b := range values {
+		if weights[b] == 0 {
+			values[b] = math.NaN()
+			continue
+		}
+		values[b] /= weights[b]
+	}
+	return values
+}
+
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// Спарклайн оценок от -1 (▁) до 1 (█), пустой интервал - пробел
+func Sparkline(values []float64) string {
+	var b strings.Builder
+	for _, v := range values {
+		if math.IsNaN(v) {
+			b.WriteRune(' ')
+			continue
+		}
+		idx := int(math.Round((max(-1, min(1, v)) + 1) / 2 * float64(len(sparkRunes)-1)))
+		b.WriteRune(sparkRunes[idx])
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package sentiment
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+var timelineColumns = []string{"file", "start", "end", "channel", "speaker", "speaker_name", "sentiment", "emotion", "score", "text"}
+
+/*
+# Таймлайн тональности в csv
+
+Строка на фрагмент анализа. Несколько результатов пишутся в один файл
+через общий writer, поэтому первая колонка - файл результата.
+*/
+type TimelineWriter struct {
+	csv    *csv.Writer
+	header bool
+}
+
+func NewTimelineWriter(w io.Writer) *TimelineWriter {
+	return &TimelineWriter{csv: csv.NewWriter(w)}
+}
+
+// Записать фрагменты одного результата; speakerName - подпись спикера, как в транскрипте
+func (tw *TimelineWriter) Write(file string, segments []Segment, speakerName func(*int) string) error {
+	if !tw.header {
+		tw.header = true
+		if err := tw.csv.Write(timelineColumns); err != nil {
+			return err
+		}
+	}
+
+	for _, seg := range segments {
+		speaker := ""
+		if seg.Speaker != nil {
+			speaker = strconv.Itoa(*seg.Speaker)
+		}
+		if err := tw.csv.Write([]string{
+			file,
+			strconv.FormatFloat(seg.Start, 'f', 3, 64),
+			strconv.FormatFloat(seg.End, 'f', 3, 64),
+			strconv.Itoa(seg.Channel),
+			speaker,
+			speakerName(seg.Speaker),
+			seg.Sentiment,
+			seg.Emotion,
+			strconv.FormatFloat(seg.Score, 'f', -1, 64),
+			seg.Text,
+		}); err != nil {
+			return err
+		}
+	}
+	tw.csv.Flush()
+	return tw.csv.Error()
+}
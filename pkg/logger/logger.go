@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Уровни логирования
+type Level = slog.Level
+
+const (
+	DEBUG = slog.LevelDebug
+	INFO  = slog.LevelInfo
+	WARN  = slog.LevelWarn
+	ERROR = slog.LevelError
+)
+
+// Форматы записей
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type ILogger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+type Options struct {
+	Level    Level
+	Format   string // text или json
+	File     string // файл логов, пусто - stderr
+	ShowText bool   // не скрывать текст транскрипции, ключи и токены скрываются всегда
+}
+
+/*
+# Логгер на log/slog
+
+Записи идут в stderr или в файл, поэтому не смешиваются с данными в
+stdout. Ключ API, токены и секреты в записях скрываются всегда, текст
+транскрипции - пока не включен Options.ShowText. Настройки можно поменять
+после разбора флагов через Setup, логгер при этом остается тем же.
+*/
+type Logger struct {
+	mu     sync.RWMutex
+	slog   *slog.Logger
+	closer io.Closer
+}
+
+// Текстовый логгер в stderr с уровнем level
+func NewLogger(level Level) *Logger {
+	l := &Logger{}
+	l.slog = slog.New(newRedactHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}), false))
+	return l
+}
+
+// Применить настройки: уровень, формат и файл логов
+func (l *Logger) Setup(opts Options) error {
+	var w io.Writer = os.Stderr
+	var closer io.Closer
+	if opts.File != "" && opts.File != "-" {
+		file, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+		if err != nil {
+			return fmt.Errorf("%s: failed open log file: %w", opts.File, err)
+		}
+		w, closer = file, file
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", FormatText:
+		handler = slog.NewTextHandler(w, handlerOpts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	default:
+		if closer != nil {
+			closer.Close()
+		}
+		return fmt.Errorf("unknown log format %q, available: text, json", opts.Format)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closer != nil {
+		l.closer.Close()
+	}
+	l.slog, l.closer = slog.New(newRedactHandler(handler, opts.ShowText)), closer
+	return nil
+}
+
+// Закрыть файл логов
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closer == nil {
+		return nil
+	}
+	err := l.closer.Close()
+	l.slog, l.closer = slog.New(slog.DiscardHandler), nil
+	return err
+}
+
+// Будут ли записаны записи уровня level, чтобы не готовить дорогие аргументы зря
+func (l *Logger) Enabled(level Level) bool {
+	return l.logger().Enabled(context.Background(), level)
+}
+
+func (l *Logger) Debug(msg string, args ...any) { l.logger().Debug(msg, args...) }
+func (l *Logger) Info(msg string, args ...any)  { l.logger().Info(msg, args...) }
+func (l *Logger) Warn(msg string, args ...any)  { l.logger().Warn(msg, args...) }
+func (l *Logger) Error(msg string, args ...any) { l.logger().Error(msg, args...) }
+
+func (l *Logger) logger() *slog.Logger {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.slog
+}
+
+/*
+# Уровень по количеству флагов -v
+
+Без флагов - level из конфигурации, -v - DEBUG с запросами и ответами API,
+-vv - DEBUG вместе с текстом транскрипции.
+*/
+func Verbosity(level Level, verbose int) (Level, bool) {
+	switch {
+	case verbose <= 0:
+		return level, false
+	case verbose == 1:
+		return min(level, DEBUG), false
+	}
+	return min(level, DEBUG), true
+}
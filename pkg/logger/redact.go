@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// Ключи, значения которых скрываются всегда
+var secretKeys = map[string]bool{
+	"x-gladia-key":    true,
+	"api_key":         true,
+	"apikey":          true,
+	"token":           true,
+	"authorization":   true,
+	"secret":          true,
+	"callback_secret": true,
+	"password":        true,
+}
+
+// Ключи с текстом транскрипции и ответами LLM. Скрываются все строки внутри значения, в том числе вложенные
+var textKeys = map[string]bool{
+	"text":            true,
+	"full_transcript": true,
+	"word":            true,
+	"results":         true,
+	"response":        true,
+	"entity":          true,
+	"subtitles":       true,
+	"summary":         true,
+	"headline":        true,
+	"abstract":        true,
+	"sentences":       true,
+}
+
+// Секреты внутри строк: параметры url и заголовок с ключом API
+var (
+	secretParam  = regexp.MustCompile(`(?i)([?&](?:secret|token|key|api_key)=)[^&\s"]+`)
+	secretHeader = regexp.MustCompile(`(?i)(x-gladia-key"?\s*[:=]\s*"?)[^"\s,}]+`)
+)
+
+// Обертка обработчика, скрывающая секреты и текст в атрибутах записи
+type redactHandler struct {
+	slog.Handler
+	showText bool
+}
+
+func newRedactHandler(h slog.Handler, showText bool) *redactHandler {
+	return &redactHandler{Handler: h, showText: showText}
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, redactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, out)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clean := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		clean[i] = h.redactAttr(a)
+	}
+	return newRedactHandler(h.Handler.WithAttrs(clean), h.showText)
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return newRedactHandler(h.Handler.WithGroup(name), h.showText)
+}
+
+func (h *redactHandler) redactAttr(a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	value := a.Value.Resolve()
+
+	switch {
+	case secretKeys[key]:
+		return slog.String(a.Key, redacted)
+	case textKeys[key] && !h.showText:
+		return slog.String(a.Key, hiddenText(value.String()))
+	case value.Kind() == slog.KindGroup:
+		group := value.Group()
+		clean := make([]any, len(group))
+		for i, ga := range group {
+			clean[i] = h.redactAttr(ga)
+		}
+		return slog.Group(a.Key, clean...)
+	case value.Kind() == slog.KindString:
+		return slog.String(a.Key, h.redactValue(value.String()))
+	case value.Kind() == slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return slog.String(a.Key, redactString(err.Error()))
+		}
+		return slog.String(a.Key, h.redactValue(fmt.Sprint(value.Any())))
+	}
+	return slog.Attr{Key: a.Key, Value: value}
+}
+
+// Строка или тело запроса в json: в json скрываются поля по ключам, в остальном - секреты в url и заголовках
+func (h *redactHandler) redactValue(s string) string {
+	trimmed := strings.TrimSpace(s)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var data any
+		if err := json.Unmarshal([]byte(trimmed), &data); err == nil {
+			if clean, err := json.Marshal(h.redactJSON(data, false)); err == nil {
+				return redactString(string(clean))
+			}
+		}
+	}
+	return redactString(s)
+}
+
+func (h *redactHandler) redactJSON(data any, isText bool) any {
+	switch v := data.(type) {
+	case map[string]any:
+		for key, value := range v {
+			lower := strings.ToLower(key)
+			if secretKeys[lower] {
+				v[key] = redacted
+				continue
+			}
+			// внутри текстового поля скрывается все, например chapterization.results[].headline
+			v[key] = h.redactJSON(value, isText || textKeys[lower] && !h.showText)
+		}
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = h.redactJSON(value, isText)
+		}
+		return v
+	case string:
+		if isText {
+			return hiddenText(v)
+		}
+		return redactString(v)
+	}
+	return data
+}
+
+func redactString(s string) string {
+	s = secretParam.ReplaceAllString(s, "${1}"+redacted)
+	return secretHeader.ReplaceAllString(s, "${1}"+redacted)
+}
+
+// Текст заменяется пометкой с его длиной, чтобы по логам было видно, что текст был
+func hiddenText(s string) string {
+	if s == "" {
+		return s
+	}
+	return fmt.Sprintf("[TEXT %d chars]", len([]rune(s)))
+}
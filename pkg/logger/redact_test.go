@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// Ответ GET /v2/pre-recorded/{id} со всеми полями, где API возвращает текст записи
+const resultBody = `{
+  "id": "45463597-20b7-4af7-b3b3-f5fb778203ab",
+  "status": "done",
+  "file": {"filename": "board-call.wav", "audio_duration": 62.5, "number_of_channels": 1},
+  "request_params": {"audio_url": "https://api.gladia.io/file/1d2e?token=abc123", "diarization": true},
+  "result": {
+    "metadata": {"audio_duration": 62.5, "billing_time": 62.5},
+    "transcription": {
+      "languages": ["en"],
+      "full_transcript": "Alice confirmed the merger with Zurich.",
+      "utterances": [{
+        "text": "Alice confirmed the merger with Zurich.",
+        "language": "en", "start": 0.4, "end": 3.1, "confidence": 0.93, "channel": 0, "speaker": 0,
+        "words": [{"word": "Alice", "start": 0.4, "end": 0.8, "confidence": 0.95}, {"word": " confirmed", "start": 0.8, "end": 1.3, "confidence": 0.9}]
+      }],
+      "subtitles": [{"format": "srt", "subtitles": "1\n00:00:00,400 --> 00:00:03,100\nAlice confirmed the merger with Zurich."}]
+    },
+    "translation": {"success": true, "results": [{"full_transcript": "Alice a confirmé la fusion avec Zurich.", "languages": ["fr"]}]},
+    "summarization": {"success": true, "results": "Alice confirmed the merger."},
+    "chapterization": {"success": true, "results": [{"headline": "Merger confirmed", "abstract": "Alice talks about Zurich.", "summary": "The merger with Zurich", "sentences": [{"sentence": "Alice confirmed the merger."}], "start": 0.4, "end": 3.1}]},
+    "named_entity_recognition": {"success": true, "entity": [{"entity_type": "PERSON", "text": "Alice", "start": 0.4, "end": 0.8}]},
+    "sentiment_analysis": {"success": true, "results": [{"text": "Alice confirmed the merger", "sentiment": "positive", "emotion": "joy", "start": 0.4, "end": 3.1}]},
+    "audio_to_llm": {"success": true, "results": [{"success": true, "results": {"prompt": "List decisions", "response": "Merger with Zurich approved by Alice"}}]},
+    "structured_data_extraction": {"success": true, "results": [{"class": "company", "value": "Zurich Holdings"}]}
+  }
+}`
+
+// Слова записи, которых не должно быть в логах без -vv
+var transcriptWords = []string{"Alice", "merger", "Zurich", "confirmé", "fusion", "Merger", "approved"}
+
+func logBody(t *testing.T, showText bool, args ...any) string {
+	t.Helper()
+	var b bytes.Buffer
+	log := slog.New(newRedactHandler(slog.NewJSONHandler(&b, &slog.HandlerOptions{Level: DEBUG}), showText))
+	log.Debug("response body", args...)
+	return b.String()
+}
+
+func TestRedactResultBody(t *testing.T) {
+	out := logBody(t, false, "body", resultBody)
+
+	for _, word := range transcriptWords {
+		if strings.Contains(out, word) {
+			t.Errorf("transcript word %q leaked into the log: %s", word, out)
+		}
+	}
+	for _, want := range []string{"45463597-20b7-4af7-b3b3-f5fb778203ab", "board-call.wav", "billing_time", "[TEXT 39 chars]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log lost %q: %s", want, out)
+		}
+	}
+	if strings.Contains(out, "abc123") {
+		t.Errorf("url token leaked into the log: %s", out)
+	}
+}
+
+func TestRedactShowText(t *testing.T) {
+	out := logBody(t, true, "body", resultBody, "x-gladia-key", "key-123")
+
+	for _, word := range transcriptWords {
+		if !strings.Contains(out, word) {
+			t.Errorf("transcript word %q hidden with ShowText: %s", word, out)
+		}
+	}
+	if strings.Contains(out, "abc123") || strings.Contains(out, "key-123") {
+		t.Errorf("secrets must stay hidden with ShowText: %s", out)
+	}
+}
+
+func TestRedactTextAttr(t *testing.T) {
+	out := logBody(t, false, slog.Group("summary", "headline", "Merger confirmed"), "text", "Alice")
+
+	for _, word := range transcriptWords {
+		if strings.Contains(out, word) {
+			t.Errorf("transcript word %q leaked into the log: %s", word, out)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Цвет неуверенных слов в формате ASS (&HBBGGRR&) - желтый
+const assLowConfidenceColour = "&H00FFFF&"
+
+func init() {
+	Register("ass", FormatterFunc(formatASS))
+	Register("ssa", FormatterFunc(formatSSA))
+}
+
+// Advanced SubStation Alpha (v4.00+) субтитры
+func formatASS(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	return writeSubStation(w, resp, opts, false)
+}
+
+// SubStation Alpha (v4.00) субтитры для старых плееров
+func formatSSA(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	return writeSubStation(w, resp, opts, true)
+}
+
+func writeSubStation(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options, legacy bool) error {
+	if resp == nil || resp.Result == nil {
+		return errNoResult
+	}
+
+	sheet := opts.StyleSheet
+	if sheet == nil {
+		sheet = DefaultStyleSheet()
+	}
+
+	cues := Cues(resp.Result, opts)
+
+	var b strings.Builder
+	scriptType := "v4.00+"
+	if legacy {
+		scriptType = "v4.00"
+	}
+	fmt.Fprintf(&b, "[Script Info]\nScriptType: %s\nPlayResX: %d\nPlayResY: %d\nWrapStyle: 2\n\n",
+		scriptType, sheet.Resolution.Width, sheet.Resolution.Height)
+
+	// стиль по умолчанию и отдельный стиль для каждого спикера, встречающегося в субтитрах
+	styles := []namedStyle{{name: "Default", style: sheet.Default}}
+	styleNames := map[int]string{}
+	for _, cue := range cues {
+		if cue.Speaker == nil {
+			continue
+		}
+		if _, ok := styleNames[*cue.Speaker]; ok {
+			continue
+		}
+		name := fmt.Sprintf("Speaker%d", *cue.Speaker+1)
+		styleNames[*cue.Speaker] = name
+		styles = append(styles, namedStyle{name: name, style: sheet.speakerStyle(*cue.Speaker)})
+	}
+
+	if legacy {
+		b.WriteString("[V4 Styles]\n")
+		b.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, TertiaryColour, BackColour, Bold, Italic, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, AlphaLevel, Encoding\n")
+		for _, s := range styles {
+			st := s.style
+			fmt.Fprintf(&b, "Style: %s,%s,%d,%s,%s,%s,%s,%d,%d,1,%g,%g,%d,%d,%d,%d,0,1\n",
+				s.name, st.Font, st.Size, ssaColour(st.Colour), ssaColour(st.SecondaryColour), ssaColour(st.OutlineColour), ssaColour(st.BackColour),
+				assBool(st.Bold), assBool(st.Italic), st.Outline, st.Shadow, ssaAlignment(st.Alignment), st.MarginL, st.MarginR, st.MarginV)
+		}
+	} else {
+		b.WriteString("[V4+ Styles]\n")
+		b.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+		for _, s := range styles {
+			st := s.style
+			fmt.Fprintf(&b, "Style: %s,%s,%d,%s,%s,%s,%s,%d,%d,0,0,100,100,0,0,1,%g,%g,%d,%d,%d,%d,1\n",
+				s.name, st.Font, st.Size, assColour(st.Colour), assColour(st.SecondaryColour), assColour(st.OutlineColour), assColour(st.BackColour),
+				assBool(st.Bold), assBool(st.Italic), st.Outline, st.Shadow, st.Alignment, st.MarginL, st.MarginR, st.MarginV)
+		}
+	}
+	b.WriteString("\n[Events]\n")
+
+	if legacy {
+		b.WriteString("Format: Marked, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	} else {
+		b.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	}
+
+	for _, cue := range cues {
+		text := assText(cue, opts)
+		if label := speakerLabel(cue.Speaker, opts); label != "" {
+			text = assEscape(label) + ": " + text
+		}
+
+		style := "Default"
+		if cue.Speaker != nil {
+			style = styleNames[*cue.Speaker]
+		}
+
+		layer := "0"
+		if legacy {
+			layer = "Marked=0"
+		}
+		fmt.Fprintf(&b, "Dialogue: %s,%s,%s,%s,%s,0,0,0,,%s\n",
+			layer, assTimecode(cue.Start), assTimecode(cue.End), style, assName(SpeakerName(cue.Speaker, opts)), text)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type namedStyle struct {
+	name  string
+	style SubStationStyle
+}
+
+// Текст события: строки через \N, в режиме караоке перед каждым словом тег \k с длительностью в сотых секунды
+func assText(cue Cue, opts Options) string {
+	if !opts.WordTimings && opts.LowConfidence <= 0 {
+		lines := make([]string, 0, len(cue.Lines))
+		for _, line := range cue.Lines {
+			lines = append(lines, assEscape(line))
+		}
+		return strings.Join(lines, `\N`)
+	}
+
+	lines := make([]string, 0, len(cue.Lines))
+	pos := cue.Start
+
+	for _, words := range cue.WordLines() {
+		parts := make([]string, 0, len(words))
+		for _, w := range words {
+			text := assEscape(strings.TrimSpace(w.Word))
+			if opts.LowConfidence > 0 && w.Confidence < opts.LowConfidence {
+				text = `{\c` + assLowConfidenceColour + `}` + text + `{\r}`
+			}
+
+			if opts.WordTimings {
+				// пауза перед словом - отдельный пустой слог, чтобы подсветка не опережала речь
+				karaoke := fmt.Sprintf(`{\k%d}`, centiseconds(w.End-w.Start))
+				if gap := centiseconds(w.Start - pos); gap > 0 {
+					karaoke = fmt.Sprintf(`{\k%d}`, gap) + karaoke
+				}
+				text = karaoke + text
+				pos = w.End
+			}
+			parts = append(parts, text)
+		}
+		lines = append(lines, strings.Join(parts, " "))
+	}
+	for _, line := range cue.Translation {
+		lines = append(lines, assEscape(line))
+	}
+	return strings.Join(lines, `\N`)
+}
+
+// Таймкод ASS: 0:00:00.00
+func assTimecode(sec float64) string {
+	cs := centiseconds(sec)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs%360000/6000, cs%6000/100, cs%100)
+}
+
+func centiseconds(sec float64) int64 {
+	if sec < 0 {
+		return 0
+	}
+	return int64(sec*100 + 0.5)
+}
+
+func assBool(v bool) int {
+	if v {
+		return -1
+	}
+	return 0
+}
+
+// Выравнивание ASS задается как на цифровой клавиатуре, в SSA верхний ряд 5-7, средний 9-11
+func ssaAlignment(numpad int) int {
+	switch {
+	case numpad >= 7:
+		return numpad - 2
+	case numpad >= 4:
+		return numpad + 5
+	default:
+		return numpad
+	}
+}
+
+// Фигурные скобки начинают блок тегов, переносы строк в событии недопустимы
+func assEscape(s string) string {
+	return strings.NewReplacer("{", "(", "}", ")", "\n", " ").Replace(s)
+}
+
+// Запятая разделяет поля события, поэтому в имени ее быть не должно
+func assName(s string) string {
+	return strings.ReplaceAll(assEscape(s), ",", " ")
+}
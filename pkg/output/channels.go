@@ -0,0 +1,55 @@
+package output
+
+import (
+	"sort"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+/*
+# Спикеры по каналам
+
+Если заданы имена каналов, спикером высказывания становится его канал, а
+имена каналов добавляются к именам спикеров: в записи звонка с оператором
+и клиентом на разных каналах подписи берутся из раскладки каналов, а не из
+диаризации. Высказывания каналов чередуются по времени начала. Исходный
+ответ не меняется, без имен каналов возвращается как есть.
+*/
+func ByChannel(resp *prerecorderv2.PreRecorderResultResponse, opts Options) (*prerecorderv2.PreRecorderResultResponse, Options) {
+	if len(opts.ChannelNames) == 0 || resp == nil || resp.Result == nil {
+		return resp, opts
+	}
+
+	names := make(map[int]string, len(opts.SpeakerNames)+len(opts.ChannelNames))
+	for id, name := range opts.SpeakerNames {
+		names[id] = name
+	}
+	for ch, name := range opts.ChannelNames {
+		names[ch] = name
+	}
+	opts.SpeakerNames = names
+
+	res := *resp.Result
+	res.Transcription = channelTranscription(res.Transcription)
+	res.Translation.Results = make([]prerecorderv2.Transcription, len(resp.Result.Translation.Results))
+	for i, t := range resp.Result.Translation.Results {
+		res.Translation.Results[i] = channelTranscription(t)
+	}
+
+	out := *resp
+	out.Result = &res
+	return &out, opts
+}
+
+func channelTranscription(t prerecorderv2.Transcription) prerecorderv2.Transcription {
+	utterances := make([]prerecorderv2.Utterance, len(t.Utterances))
+	for i, u := range t.Utterances {
+		channel := u.Channel
+		u.Speaker = &channel
+		utterances[i] = u
+	}
+	sort.SliceStable(utterances, func(i, j int) bool { return utterances[i].Start < utterances[j].Start })
+
+	t.Utterances = utterances
+	return t
+}
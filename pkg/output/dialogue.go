@@ -0,0 +1,102 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+	"gopkg.in/yaml.v3"
+)
+
+// Реплика диалога: подряд идущие высказывания одного спикера
+type Turn struct {
+	Speaker *int
+	Start   float64
+	End     float64
+	Text    string
+}
+
+// Объединить подряд идущие высказывания одного спикера в реплики
+func Dialogue(res *prerecorderv2.Result) []Turn {
+	var turns []Turn
+	for _, u := range res.Transcription.Utterances {
+		text := strings.TrimSpace(u.Text)
+		if text == "" {
+			continue
+		}
+
+		if n := len(turns); n > 0 && sameSpeaker(turns[n-1].Speaker, u.Speaker) {
+			turns[n-1].Text += " " + text
+			turns[n-1].End = u.End
+			continue
+		}
+		turns = append(turns, Turn{Speaker: u.Speaker, Start: u.Start, End: u.End, Text: text})
+	}
+	return turns
+}
+
+// Есть ли в результате информация о спикерах
+func hasSpeakers(res *prerecorderv2.Result) bool {
+	for _, u := range res.Transcription.Utterances {
+		if u.Speaker != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func sameSpeaker(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Имя спикера: из SpeakerNames или "Speaker N", нумерация с единицы
+func SpeakerName(speaker *int, opts Options) string {
+	if speaker == nil {
+		return ""
+	}
+	if name, ok := opts.SpeakerNames[*speaker]; ok {
+		return name
+	}
+	return fmt.Sprintf("Speaker %d", *speaker+1)
+}
+
+/*
+# Разобрать имена спикеров
+
+Значение - список "0=Alice,1=Bob" или путь к yaml файлу:
+
+	0: Alice
+	1: Bob
+*/
+func ParseSpeakerNames(value string) (map[int]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	names := map[int]string{}
+	if !strings.Contains(value, "=") {
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed read speaker names: %w", value, err)
+		}
+		if err := yaml.Unmarshal(data, &names); err != nil {
+			return nil, fmt.Errorf("%s: failed parse speaker names: %w", value, err)
+		}
+		return names, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		key, name, ok := strings.Cut(pair, "=")
+		id, err := strconv.Atoi(strings.TrimSpace(key))
+		if !ok || err != nil || id < 0 {
+			return nil, fmt.Errorf("invalid speaker name %q, expected id=name", pair)
+		}
+		names[id] = strings.TrimSpace(name)
+	}
+	return names, nil
+}
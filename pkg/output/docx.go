@@ -0,0 +1,237 @@
+package output
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+func init() {
+	Register("docx", FormatterFunc(formatDOCX))
+}
+
+/*
+# DOCX документ
+
+Минимальный пакет Office Open XML без внешних инструментов: таблица
+метаданных задачи, абзац на реплику с таймкодом и именем спикера.
+LineNumbers включает нумерацию строк на полях, LowConfidence выделяет
+неуверенные слова желтой заливкой.
+*/
+func formatDOCX(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	if resp == nil || resp.Result == nil {
+		return errNoResult
+	}
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRootRels},
+		{"docProps/core.xml", docxCoreProps(resp)},
+		{"word/_rels/document.xml.rels", docxDocumentRels},
+		{"word/styles.xml", docxStyles},
+		{"word/document.xml", docxDocument(resp, opts)},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("docx: %s: %w", part.name, err)
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return fmt.Errorf("docx: %s: %w", part.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// Абзац документа: подряд идущие высказывания одного спикера
+type docxParagraph struct {
+	speaker *int
+	start   float64
+	words   []prerecorderv2.Word
+}
+
+func docxParagraphs(res *prerecorderv2.Result) []docxParagraph {
+	var paragraphs []docxParagraph
+	for _, u := range res.Transcription.Utterances {
+		words := u.Words
+		if len(words) == 0 {
+			words = spreadWords(u)
+		}
+		if len(words) == 0 {
+			continue
+		}
+
+		if n := len(paragraphs); n > 0 && paragraphs[n-1].speaker != nil && sameSpeaker(paragraphs[n-1].speaker, u.Speaker) {
+			paragraphs[n-1].words = append(paragraphs[n-1].words, words...)
+			continue
+		}
+		paragraphs = append(paragraphs, docxParagraph{speaker: u.Speaker, start: u.Start, words: words})
+	}
+	return paragraphs
+}
+
+func docxDocument(resp *prerecorderv2.PreRecorderResultResponse, opts Options) string {
+	res := resp.Result
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+
+	title := "Transcription"
+	if resp.File != nil && resp.File.Filename != "" {
+		title = resp.File.Filename
+	}
+	fmt.Fprintf(&b, `<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr>%s</w:p>`, docxRun(title, ""))
+
+	if fields := reportMetadata(resp); len(fields) > 0 {
+		b.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="MetadataTable"/><w:tblW w:w="0" w:type="auto"/></w:tblPr><w:tblGrid><w:gridCol w:w="2800"/><w:gridCol w:w="6200"/></w:tblGrid>`)
+		for _, f := range fields {
+			fmt.Fprintf(&b, `<w:tr><w:tc><w:tcPr><w:tcW w:w="2800" w:type="dxa"/></w:tcPr><w:p>%s</w:p></w:tc><w:tc><w:tcPr><w:tcW w:w="6200" w:type="dxa"/></w:tcPr><w:p>%s</w:p></w:tc></w:tr>`,
+				docxRun(f.Name, `<w:b/>`), docxRun(f.Value, ""))
+		}
+		b.WriteString(`</w:tbl><w:p/>`)
+	}
+
+	if summary := res.Summarization.Results; summary != "" {
+		fmt.Fprintf(&b, `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr>%s</w:p>`, docxRun("Summary", ""))
+		for _, line := range strings.Split(summary, "\n") {
+			fmt.Fprintf(&b, `<w:p>%s</w:p>`, docxRun(line, ""))
+		}
+	}
+
+	fmt.Fprintf(&b, `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr>%s</w:p>`, docxRun("Transcript", ""))
+	for _, p := range docxParagraphs(res) {
+		b.WriteString(`<w:p><w:pPr><w:pStyle w:val="Transcript"/></w:pPr>`)
+		b.WriteString(docxRun("["+FormatClock(p.start)+"] ", `<w:rStyle w:val="Timestamp"/>`))
+		if name := SpeakerName(p.speaker, opts); name != "" {
+			b.WriteString(docxRun(name+": ", `<w:b/>`))
+		}
+		b.WriteString(docxWords(p.words, opts.LowConfidence))
+		b.WriteString(`</w:p>`)
+	}
+
+	// лист A4, поля 2 см, нумерация строк с каждой строки и сквозная по документу.
+	// Порядок элементов sectPr задан схемой: lnNumType идет после pgSz и pgMar
+	b.WriteString(`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:pgMar w:top="1134" w:right="1134" w:bottom="1134" w:left="1134" w:header="709" w:footer="709" w:gutter="0"/>`)
+	if opts.LineNumbers {
+		b.WriteString(`<w:lnNumType w:countBy="1" w:distance="340" w:restart="continuous"/>`)
+	}
+	b.WriteString(`</w:sectPr>`)
+	b.WriteString(`</w:body></w:document>`)
+	return b.String()
+}
+
+// Слова реплики: подряд идущие слова с одинаковым выделением объединяются в один run
+func docxWords(words []prerecorderv2.Word, threshold float64) string {
+	var b strings.Builder
+	var text []string
+	lowRun := false
+
+	flush := func() {
+		if len(text) == 0 {
+			return
+		}
+		props := ""
+		if lowRun {
+			props = `<w:highlight w:val="yellow"/>`
+		}
+		b.WriteString(docxRun(strings.Join(text, " "), props))
+		text = text[:0]
+	}
+
+	for i, w := range words {
+		word := strings.TrimSpace(w.Word)
+		if word == "" {
+			continue
+		}
+		low := threshold > 0 && w.Confidence < threshold
+		if low != lowRun {
+			flush()
+			lowRun = low
+		}
+		// пробел между словами остается в невыделенном run, чтобы заливка не выходила за слово
+		if i > 0 && len(text) == 0 && b.Len() > 0 {
+			b.WriteString(docxRun(" ", ""))
+		}
+		text = append(text, word)
+	}
+	flush()
+	return b.String()
+}
+
+// Run с текстом, props - свойства run (w:rPr)
+func docxRun(text string, props string) string {
+	var b strings.Builder
+	b.WriteString(`<w:r>`)
+	if props != "" {
+		b.WriteString(`<w:rPr>` + props + `</w:rPr>`)
+	}
+	fmt.Fprintf(&b, `<w:t xml:space="preserve">%s</w:t></w:r>`, xmlEscape(docxText(text)))
+	return b.String()
+}
+
+// Управляющие символы запрещены в XML документа Word
+func docxText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+func docxCoreProps(resp *prerecorderv2.PreRecorderResultResponse) string {
+	title := resp.ID
+	if resp.File != nil && resp.File.Filename != "" {
+		title = resp.File.Filename
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	return xml.Header + `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">` +
+		`<dc:title>` + xmlEscape(docxText(title)) + `</dc:title>` +
+		`<dc:creator>gladia-cli</dc:creator>` +
+		`<dcterms:created xsi:type="dcterms:W3CDTF">` + now + `</dcterms:created>` +
+		`<dcterms:modified xsi:type="dcterms:W3CDTF">` + now + `</dcterms:modified>` +
+		`</cp:coreProperties>`
+}
+
+const docxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+	`</Types>`
+
+const docxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+	`</Relationships>`
+
+const docxDocumentRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+const docxStyles = xml.Header + `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:cs="Calibri"/><w:sz w:val="22"/></w:rPr></w:rPrDefault>` +
+	`<w:pPrDefault><w:pPr><w:spacing w:after="120"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+	`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:pPr><w:spacing w:after="240"/></w:pPr><w:rPr><w:b/><w:sz w:val="36"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:pPr><w:keepNext/><w:spacing w:before="240"/><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:sz w:val="28"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Transcript"><w:name w:val="Transcript"/><w:basedOn w:val="Normal"/><w:pPr><w:spacing w:line="360" w:lineRule="auto"/></w:pPr></w:style>` +
+	`<w:style w:type="character" w:styleId="Timestamp"><w:name w:val="Timestamp"/><w:rPr><w:rFonts w:ascii="Consolas" w:hAnsi="Consolas"/><w:color w:val="808080"/></w:rPr></w:style>` +
+	`<w:style w:type="table" w:styleId="MetadataTable"><w:name w:val="Metadata Table"/><w:tblPr><w:tblBorders>` +
+	`<w:top w:val="single" w:sz="4" w:space="0" w:color="BFBFBF"/><w:left w:val="single" w:sz="4" w:space="0" w:color="BFBFBF"/>` +
+	`<w:bottom w:val="single" w:sz="4" w:space="0" w:color="BFBFBF"/><w:right w:val="single" w:sz="4" w:space="0" w:color="BFBFBF"/>` +
+	`<w:insideH w:val="single" w:sz="4" w:space="0" w:color="BFBFBF"/><w:insideV w:val="single" w:sz="4" w:space="0" w:color="BFBFBF"/>` +
+	`</w:tblBorders><w:tblCellMar><w:left w:w="108" w:type="dxa"/><w:right w:w="108" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>` +
+	`</w:styles>`
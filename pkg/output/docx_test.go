@@ -0,0 +1,174 @@
+package output
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"slices"
+	"testing"
+)
+
+// Произвольный элемент XML с вложенными элементами
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []xmlNode  `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+func (n xmlNode) children(local string) []xmlNode {
+	var nodes []xmlNode
+	for _, c := range n.Nodes {
+		if c.XMLName.Local == local {
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
+
+func (n xmlNode) child(local string) xmlNode {
+	if nodes := n.children(local); len(nodes) > 0 {
+		return nodes[0]
+	}
+	return xmlNode{}
+}
+
+func (n xmlNode) attr(local string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (n xmlNode) names() []string {
+	var names []string
+	for _, c := range n.Nodes {
+		names = append(names, c.XMLName.Local)
+	}
+	return names
+}
+
+// Текст абзаца по run, свойства run сводятся к имени первого элемента rPr
+type docxTestRun struct {
+	props string
+	text  string
+}
+
+func paragraphRuns(p xmlNode) []docxTestRun {
+	var runs []docxTestRun
+	for _, r := range p.children("r") {
+		run := docxTestRun{text: r.child("t").Text}
+		if props := r.child("rPr").Nodes; len(props) > 0 {
+			run.props = props[0].XMLName.Local + "=" + props[0].attr("val")
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+func renderDOCX(t *testing.T, opts Options) xmlNode {
+	t.Helper()
+	resp := loadResult(t)
+	resp.Result.Transcription.Utterances[0].Words[2].Confidence = 0.3 // "welcome"
+
+	var b bytes.Buffer
+	if err := formatDOCX(&b, resp, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := zr.Open("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc xmlNode
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("word/document.xml: %s", err)
+	}
+	return doc.child("body")
+}
+
+func TestDOCXDocument(t *testing.T) {
+	body := renderDOCX(t, Options{LowConfidence: 0.5, LineNumbers: true})
+
+	metadata := map[string]string{}
+	for _, tr := range body.child("tbl").children("tr") {
+		cells := tr.children("tc")
+		if len(cells) != 2 {
+			t.Fatalf("metadata row with %d cells", len(cells))
+		}
+		metadata[cells[0].child("p").child("r").child("t").Text] = cells[1].child("p").child("r").child("t").Text
+	}
+	for name, want := range map[string]string{"File": "interview.wav", "Task ID": "a1b2c3d4", "Languages": "en"} {
+		if metadata[name] != want {
+			t.Errorf("metadata %q = %q, want %q", name, metadata[name], want)
+		}
+	}
+
+	var transcript [][]docxTestRun
+	for _, p := range body.children("p") {
+		if p.child("pPr").child("pStyle").attr("val") == "Transcript" {
+			transcript = append(transcript, paragraphRuns(p))
+		}
+	}
+	want := [][]docxTestRun{
+		{
+			{"rStyle=Timestamp", "[00:00:01] "},
+			{"b=", "Speaker 1: "},
+			{"", "Hello &"},
+			{"", " "},
+			{"highlight=yellow", "welcome"},
+			{"", " "},
+			{"", "to the café."},
+		},
+		{
+			{"rStyle=Timestamp", "[00:00:59] "},
+			{"b=", "Speaker 2: "},
+			{"", "Thanks, glad to be here."},
+		},
+		{
+			{"rStyle=Timestamp", "[00:09:59] "},
+			{"b=", "Speaker 1: "},
+			{"", "Ten minutes <already>."},
+		},
+	}
+	if len(transcript) != len(want) {
+		t.Fatalf("got %d transcript paragraphs, want %d", len(transcript), len(want))
+	}
+	for i := range want {
+		if !slices.Equal(transcript[i], want[i]) {
+			t.Errorf("paragraph %d:\ngot  %q\nwant %q", i+1, transcript[i], want[i])
+		}
+	}
+
+	if got := body.child("sectPr").names(); !slices.Equal(got, []string{"pgSz", "pgMar", "lnNumType"}) {
+		t.Errorf("sectPr children %v, want lnNumType after pgSz and pgMar", got)
+	}
+}
+
+func TestDOCXWithoutLineNumbers(t *testing.T) {
+	body := renderDOCX(t, Options{})
+
+	if got := body.child("sectPr").names(); !slices.Equal(got, []string{"pgSz", "pgMar"}) {
+		t.Errorf("sectPr children %v, want only pgSz and pgMar", got)
+	}
+	for _, p := range body.children("p") {
+		for _, run := range paragraphRuns(p) {
+			if run.props == "highlight=yellow" {
+				t.Errorf("run %q highlighted without a confidence threshold", run.text)
+			}
+		}
+	}
+}
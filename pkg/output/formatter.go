@@ -1 +1,107 @@
 package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Настройки рендеринга результата, общие для всех форматов
+type Options struct {
+	MaxLineLength  int            // максимальная длина строки субтитра в символах
+	MaxLines       int            // максимальное количество строк в одном субтитре
+	MaxCueDuration float64        // максимальная длительность субтитра в секундах
+	SpeakerLabels  bool           // подписывать спикеров
+	SpeakerNames   map[int]string // имена спикеров по номеру, включают подписи
+	ChannelNames   map[int]string // имена каналов, заменяют спикеров диаризации
+	Bilingual      string         // язык перевода, который выводится в субтитрах под оригиналом
+	WordTimings    bool           // пословные таймкоды в субтитрах (караоке)
+	LowConfidence  float64        // выделять слова с уверенностью ниже порога, 0 - не выделять
+	StyleSheet     *StyleSheet    // стили ASS/SSA субтитров, nil - стили по умолчанию
+	FrameRate      *FrameRate     // частота кадров для таймкодов TTML и EBU-STL, nil - медиавремя
+	Rows           string         // строки табличных форматов: utterance или word
+	AudioPath      string         // локальный аудиофайл или ссылка для плеера в отчетах md и html
+	LineNumbers    bool           // нумерация строк в docx
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxLineLength <= 0 {
+		o.MaxLineLength = defaultMaxLineLength
+	}
+	if o.MaxLines <= 0 {
+		o.MaxLines = defaultMaxLines
+	}
+	if o.MaxCueDuration <= 0 {
+		o.MaxCueDuration = defaultMaxCueDuration
+	}
+	return o
+}
+
+// Форматирует результат транскрибации в конкретный формат вывода
+type Formatter interface {
+	Format(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error
+}
+
+type FormatterFunc func(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error
+
+func (f FormatterFunc) Format(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	return f(w, resp, opts)
+}
+
+var formatters = map[string]Formatter{}
+
+// Общая подготовка перед любым форматом: спикеры по каналам и проверка перевода для двуязычных субтитров
+type registered struct {
+	Formatter
+}
+
+func (f registered) Format(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	if err := checkBilingual(resp, opts); err != nil {
+		return err
+	}
+	resp, opts = ByChannel(resp, opts)
+	return f.Formatter.Format(w, resp, opts)
+}
+
+// Зарегистрировать формат вывода под именем, совпадающим с расширением файла
+func Register(name string, f Formatter) {
+	formatters[name] = registered{f}
+}
+
+// Получить формат вывода по имени
+func Get(name string) (Formatter, error) {
+	f, ok := formatters[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q, available: %s", name, strings.Join(Names(), ", "))
+	}
+	return f, nil
+}
+
+// Получить формат вывода по расширению файла (result.srt -> srt)
+func ByExtension(filePath string) (Formatter, error) {
+	ext := strings.TrimPrefix(filepath.Ext(filePath), ".")
+	if ext == "" {
+		return nil, fmt.Errorf("%s: unable to detect output format from file extension", filePath)
+	}
+	return Get(ext)
+}
+
+// Список зарегистрированных форматов
+func Names() []string {
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Время в секундах в формате 00:00:00
+func FormatClock(sec float64) string {
+	total := int(sec)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total%3600/60, total%60)
+}
@@ -0,0 +1,93 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// go test ./pkg/output -update перезаписывает эталоны в testdata
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// Частоты кадров, на которых проверяются форматы с таймкодами в кадрах
+var goldenFrameRates = []string{"23.976", "25", "29.97"}
+
+func loadResult(t *testing.T) *prerecorderv2.PreRecorderResultResponse {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "result.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp prerecorderv2.PreRecorderResultResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatal(err)
+	}
+	return &resp
+}
+
+func frameRate(t *testing.T, name string) *FrameRate {
+	t.Helper()
+	fr, err := ParseFrameRate(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fr
+}
+
+// Отрендерить результат зарегистрированным форматом
+func render(t *testing.T, format string, opts Options) ([]byte, error) {
+	t.Helper()
+	f, err := Get(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	err = f.Format(&b, loadResult(t), opts)
+	return b.Bytes(), err
+}
+
+// Сравнить вывод с эталоном testdata/<name>.golden
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%s (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s: output differs from golden file\ngot:\n%q\nwant:\n%q", name, got, want)
+	}
+}
+
+// Номер кадра по компонентам таймкода, обратное преобразование к FrameRate.Split
+func timecodeFrames(fr FrameRate, hh, mm, ss, ff int64) int64 {
+	nominal := int64(fr.Nominal)
+	frames := ((hh*60+mm)*60+ss)*nominal + ff
+	if fr.Drop {
+		minutes := hh*60 + mm
+		frames -= 2 * (minutes - minutes/10)
+	}
+	return frames
+}
+
+// Время, прочитанное из файла, совпадает с исходным с точностью до кадра
+func checkFrameTime(t *testing.T, what string, fr FrameRate, frames int64, want float64) {
+	t.Helper()
+	got := float64(frames) * float64(fr.Den) / float64(fr.Num)
+	if frame := float64(fr.Den) / float64(fr.Num); math.Abs(got-want) > frame {
+		t.Errorf("%s at %s fps: got %.3fs, want %.3fs within one frame", what, fr.Name, got, want)
+	}
+}
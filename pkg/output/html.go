@@ -0,0 +1,274 @@
+package output
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+func init() {
+	Register("html", FormatterFunc(formatHTML))
+}
+
+/*
+# HTML отчет
+
+Один самодостаточный файл без внешних ресурсов: стили и скрипт встроены,
+локальный аудиофайл встраивается в плеер как data URI. Таймкоды реплик
+перематывают плеер, без аудио ведут на якорь реплики.
+*/
+func formatHTML(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	if resp == nil || resp.Result == nil {
+		return errNoResult
+	}
+	res := resp.Result
+
+	audio, err := audioSource(opts.AudioPath)
+	if err != nil {
+		return err
+	}
+
+	data := htmlReport{
+		Title:    "Transcription",
+		Metadata: reportMetadata(resp),
+		Summary:  res.Summarization.Results,
+		Audio:    audio,
+	}
+	if resp.File != nil && resp.File.Filename != "" {
+		data.Title = resp.File.Filename
+	}
+
+	turns := reportTurns(res)
+	for _, ch := range res.Chapterization.Results {
+		chapter := htmlChapter{
+			Headline: ch.Headline,
+			Span:     FormatClock(ch.Start) + " - " + FormatClock(ch.End),
+			Summary:  ch.Summary,
+			Keywords: strings.Join(ch.Keywords, ", "),
+		}
+		if start, ok := turnAt(turns, ch.Start); ok {
+			chapter.Anchor = timeAnchor(start)
+			chapter.Seconds = fmt.Sprintf("%.2f", start)
+		}
+		data.Chapters = append(data.Chapters, chapter)
+	}
+
+	for _, item := range res.AudioToLLM.Results {
+		data.Prompts = append(data.Prompts, htmlPrompt{Prompt: item.Results.Prompt, Response: item.Results.Response})
+	}
+
+	if hasSpeakers(res) {
+		for _, st := range SpeakerTalkTime(res) {
+			name := SpeakerName(st.Speaker, opts)
+			if name == "" {
+				name = "Unknown"
+			}
+			data.TalkTime = append(data.TalkTime, htmlTalkTime{
+				Speaker: name,
+				Time:    FormatClock(st.Duration),
+				Share:   fmt.Sprintf("%.0f%%", st.Share*100),
+				Turns:   st.Turns,
+				Words:   st.Words,
+			})
+		}
+	}
+
+	for _, turn := range turns {
+		entry := htmlTurn{
+			Anchor:  timeAnchor(turn.Start),
+			Seconds: fmt.Sprintf("%.2f", turn.Start),
+			Clock:   FormatClock(turn.Start),
+			Speaker: SpeakerName(turn.Speaker, opts),
+			Text:    turn.Text,
+		}
+		if turn.Speaker != nil {
+			entry.Class = fmt.Sprintf("speaker-%d", *turn.Speaker%8)
+		}
+		data.Turns = append(data.Turns, entry)
+	}
+	if len(turns) == 0 {
+		data.FullTranscript = res.Transcription.FullTranscript
+	}
+
+	return htmlTemplate.Execute(w, data)
+}
+
+type htmlReport struct {
+	Title          string
+	Metadata       []reportField
+	Summary        string
+	Chapters       []htmlChapter
+	Prompts        []htmlPrompt
+	TalkTime       []htmlTalkTime
+	Turns          []htmlTurn
+	FullTranscript string
+	Audio          template.URL
+}
+
+type htmlChapter struct {
+	Headline string
+	Span     string
+	Anchor   string
+	Seconds  string
+	Summary  string
+	Keywords string
+}
+
+type htmlPrompt struct {
+	Prompt   string
+	Response string
+}
+
+type htmlTalkTime struct {
+	Speaker string
+	Time    string
+	Share   string
+	Turns   int
+	Words   int
+}
+
+type htmlTurn struct {
+	Anchor  string
+	Seconds string
+	Clock   string
+	Speaker string
+	Class   string
+	Text    string
+}
+
+// Источник аудио для плеера: локальный файл встраивается как data URI, ссылка используется как есть
+func audioSource(audioPath string) (template.URL, error) {
+	if audioPath == "" {
+		return "", nil
+	}
+	if strings.HasPrefix(audioPath, "http://") || strings.HasPrefix(audioPath, "https://") {
+		return template.URL(audioPath), nil
+	}
+
+	content, err := os.ReadFile(audioPath)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed read audio for html report: %w", audioPath, err)
+	}
+
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(audioPath)))
+	if mimeType == "" || !strings.HasPrefix(mimeType, "audio/") && !strings.HasPrefix(mimeType, "video/") {
+		mimeType = "audio/mpeg"
+	}
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return template.URL("data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(content)), nil
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 860px; margin: 0 auto; padding: 1.5em; color: #222; line-height: 1.5; }
+h1 { margin-top: 0; }
+h2 { border-bottom: 1px solid #ddd; padding-bottom: .2em; margin-top: 1.8em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ddd; padding: .3em .7em; text-align: left; }
+td.num { text-align: right; }
+.player { position: sticky; top: 0; background: #fff; padding: .5em 0; z-index: 1; }
+.player audio { width: 100%; }
+.turn { margin: .6em 0; padding: .2em .5em; border-left: 3px solid #ccc; }
+.turn.playing { background: #fff8dc; }
+.time { font-family: monospace; color: #555; text-decoration: none; margin-right: .5em; }
+.time:hover { text-decoration: underline; }
+.speaker { font-weight: bold; margin-right: .3em; }
+.speaker-0 { border-color: #1f77b4; } .speaker-1 { border-color: #ff7f0e; }
+.speaker-2 { border-color: #2ca02c; } .speaker-3 { border-color: #d62728; }
+.speaker-4 { border-color: #9467bd; } .speaker-5 { border-color: #8c564b; }
+.speaker-6 { border-color: #e377c2; } .speaker-7 { border-color: #17becf; }
+.keywords { color: #555; font-style: italic; }
+blockquote { margin: 0 0 .5em; padding-left: 1em; border-left: 3px solid #ddd; color: #555; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Metadata}}
+<table>
+{{- range .Metadata}}
+<tr><th>{{.Name}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Audio}}
+<div class="player"><audio id="player" controls preload="metadata" src="{{.Audio}}"></audio></div>
+{{- end}}
+{{- if .Summary}}
+<h2>Summary</h2>
+<p>{{.Summary}}</p>
+{{- end}}
+{{- if .Chapters}}
+<h2>Chapters</h2>
+{{- range .Chapters}}
+<h3>{{.Headline}} {{if .Anchor}}<a class="time" href="#{{.Anchor}}" data-t="{{.Seconds}}">{{.Span}}</a>{{else}}<span class="time">{{.Span}}</span>{{end}}</h3>
+{{- if .Summary}}
+<p>{{.Summary}}</p>
+{{- end}}
+{{- if .Keywords}}
+<p class="keywords">Keywords: {{.Keywords}}</p>
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Prompts}}
+<h2>Prompts</h2>
+{{- range .Prompts}}
+<blockquote>{{.Prompt}}</blockquote>
+<p>{{.Response}}</p>
+{{- end}}
+{{- end}}
+{{- if .TalkTime}}
+<h2>Talk time</h2>
+<table>
+<tr><th>Speaker</th><th>Time</th><th>Share</th><th>Turns</th><th>Words</th></tr>
+{{- range .TalkTime}}
+<tr><td>{{.Speaker}}</td><td class="num">{{.Time}}</td><td class="num">{{.Share}}</td><td class="num">{{.Turns}}</td><td class="num">{{.Words}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<h2>Transcript</h2>
+{{- range .Turns}}
+<p class="turn {{.Class}}" id="{{.Anchor}}" data-start="{{.Seconds}}"><a class="time" href="#{{.Anchor}}" data-t="{{.Seconds}}">{{.Clock}}</a>{{if .Speaker}}<span class="speaker">{{.Speaker}}:</span>{{end}}{{.Text}}</p>
+{{- end}}
+{{- if .FullTranscript}}
+<p>{{.FullTranscript}}</p>
+{{- end}}
+{{- if .Audio}}
+<script>
+(function () {
+  var player = document.getElementById("player");
+  var turns = Array.prototype.slice.call(document.querySelectorAll(".turn"));
+  document.querySelectorAll("a.time").forEach(function (link) {
+    link.addEventListener("click", function (e) {
+      e.preventDefault();
+      player.currentTime = parseFloat(link.dataset.t);
+      player.play();
+    });
+  });
+  player.addEventListener("timeupdate", function () {
+    var current = null;
+    turns.forEach(function (turn) {
+      if (parseFloat(turn.dataset.start) <= player.currentTime) { current = turn; }
+      turn.classList.remove("playing");
+    });
+    if (current) { current.classList.add("playing"); }
+  });
+})();
+</script>
+{{- end}}
+</body>
+</html>
+`))
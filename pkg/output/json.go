@@ -0,0 +1,19 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+func init() {
+	Register("json", FormatterFunc(formatJSON))
+}
+
+// Полный ответ API без изменений
+func formatJSON(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(resp)
+}
@@ -0,0 +1,114 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+func init() {
+	Register("md", FormatterFunc(formatMarkdown))
+}
+
+/*
+# Markdown отчет
+
+Метаданные файла, резюме, главы, ответы LLM, время речи спикеров и
+транскрипт. Таймкоды глав ведут на реплики транскрипта, таймкоды реплик
+открывают аудио с нужного места, если известен путь к файлу.
+*/
+func formatMarkdown(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	if resp == nil || resp.Result == nil {
+		return errNoResult
+	}
+	res := resp.Result
+
+	turns := reportTurns(res)
+
+	var b strings.Builder
+	b.WriteString("# Transcription\n\n")
+
+	if fields := reportMetadata(resp); len(fields) > 0 {
+		b.WriteString("| | |\n|---|---|\n")
+		for _, f := range fields {
+			fmt.Fprintf(&b, "| **%s** | %s |\n", f.Name, mdCell(f.Value))
+		}
+		b.WriteString("\n")
+	}
+
+	if summary := res.Summarization.Results; summary != "" {
+		fmt.Fprintf(&b, "## Summary\n\n%s\n\n", summary)
+	}
+
+	if chapters := res.Chapterization.Results; len(chapters) > 0 {
+		b.WriteString("## Chapters\n\n")
+		for _, ch := range chapters {
+			span := FormatClock(ch.Start) + " - " + FormatClock(ch.End)
+			if start, ok := turnAt(turns, ch.Start); ok {
+				span = fmt.Sprintf("[%s](#%s)", span, timeAnchor(start))
+			}
+			fmt.Fprintf(&b, "### %s %s\n\n", ch.Headline, span)
+			if ch.Summary != "" {
+				fmt.Fprintf(&b, "%s\n\n", ch.Summary)
+			}
+			if len(ch.Keywords) > 0 {
+				fmt.Fprintf(&b, "*Keywords:* %s\n\n", strings.Join(ch.Keywords, ", "))
+			}
+		}
+	}
+
+	if items := res.AudioToLLM.Results; len(items) > 0 {
+		b.WriteString("## Prompts\n\n")
+		for _, item := range items {
+			fmt.Fprintf(&b, "> %s\n\n%s\n\n", item.Results.Prompt, item.Results.Response)
+		}
+	}
+
+	if hasSpeakers(res) {
+		b.WriteString("## Talk time\n\n| Speaker | Time | Share | Turns | Words |\n|---|---:|---:|---:|---:|\n")
+		for _, st := range SpeakerTalkTime(res) {
+			name := SpeakerName(st.Speaker, opts)
+			if name == "" {
+				name = "Unknown"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %.0f%% | %d | %d |\n", mdCell(name), FormatClock(st.Duration), st.Share*100, st.Turns, st.Words)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Transcript\n\n")
+	for _, turn := range turns {
+		stamp := fmt.Sprintf("[`%s`](%s)", FormatClock(turn.Start), mdTimeLink(opts.AudioPath, turn.Start))
+		if turn.Speaker != nil {
+			fmt.Fprintf(&b, "<a id=\"%s\"></a>%s **%s:** %s\n\n", timeAnchor(turn.Start), stamp, SpeakerName(turn.Speaker, opts), turn.Text)
+		} else {
+			fmt.Fprintf(&b, "<a id=\"%s\"></a>%s %s\n\n", timeAnchor(turn.Start), stamp, turn.Text)
+		}
+	}
+	if len(turns) == 0 && res.Transcription.FullTranscript != "" {
+		fmt.Fprintf(&b, "%s\n", res.Transcription.FullTranscript)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Ссылка таймкода: аудио с нужной секунды (media fragment) или якорь реплики
+func mdTimeLink(audioPath string, sec float64) string {
+	if audioPath == "" {
+		return "#" + timeAnchor(sec)
+	}
+	link := audioPath
+	if !strings.Contains(link, "://") {
+		link = filepath.ToSlash(link)
+	}
+	return fmt.Sprintf("<%s#t=%.2f>", link, sec)
+}
+
+// Вертикальная черта и переводы строк ломают таблицу markdown
+func mdCell(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "|", "\\|")
+}
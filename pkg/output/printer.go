@@ -10,6 +10,7 @@ type IOutput interface {
 }
 
 type Output struct {
+	IsVerbose bool
 }
 
 func (*Output) Print(a ...any) {
@@ -20,3 +21,15 @@ func (*Output) Printf(format string, a ...any) {
 	fmt.Printf(format, a...)
 	fmt.Println()
 }
+
+func (o *Output) Verbose(a ...any) {
+	if o.IsVerbose {
+		o.Print(a...)
+	}
+}
+
+func (o *Output) FVerbose(format string, a ...any) {
+	if o.IsVerbose {
+		o.Printf(format, a...)
+	}
+}
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Время речи одного спикера
+type TalkTime struct {
+	Speaker  *int
+	Duration float64 // суммарная длительность высказываний в секундах
+	Share    float64 // доля от общего времени речи, 0-1
+	Turns    int     // количество реплик
+	Words    int
+}
+
+// Время речи по спикерам, по убыванию длительности
+func SpeakerTalkTime(res *prerecorderv2.Result) []TalkTime {
+	stats := map[int]*TalkTime{}
+	var order []int
+	total := 0.0
+
+	get := func(speaker *int) *TalkTime {
+		key := -1
+		if speaker != nil {
+			key = *speaker
+		}
+		st, ok := stats[key]
+		if !ok {
+			st = &TalkTime{Speaker: speaker}
+			stats[key] = st
+			order = append(order, key)
+		}
+		return st
+	}
+
+	for _, u := range res.Transcription.Utterances {
+		st := get(u.Speaker)
+		st.Duration += u.End - u.Start
+		st.Words += len(strings.Fields(u.Text))
+		total += u.End - u.Start
+	}
+	for _, turn := range Dialogue(res) {
+		get(turn.Speaker).Turns++
+	}
+
+	talk := make([]TalkTime, 0, len(order))
+	for _, key := range order {
+		st := *stats[key]
+		if total > 0 {
+			st.Share = st.Duration / total
+		}
+		talk = append(talk, st)
+	}
+	sort.SliceStable(talk, func(i, j int) bool { return talk[i].Duration > talk[j].Duration })
+	return talk
+}
+
+// Поле метаданных отчета
+type reportField struct {
+	Name  string
+	Value string
+}
+
+// Метаданные файла и задачи для шапки отчета, пустые значения пропускаются
+func reportMetadata(resp *prerecorderv2.PreRecorderResultResponse) []reportField {
+	res := resp.Result
+	var fields []reportField
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, reportField{Name: name, Value: value})
+		}
+	}
+
+	duration := res.Metadata.AudioDuration
+	channels := res.Metadata.NumberOfDistinctChannels
+	if resp.File != nil {
+		add("File", resp.File.Filename)
+		if duration == 0 {
+			duration = resp.File.AudioDuration
+		}
+		if channels == 0 {
+			channels = resp.File.NumberOfChannels
+		}
+	}
+	add("Task ID", resp.ID)
+	add("Created", resp.CreatedAt)
+	if duration > 0 {
+		add("Duration", FormatClock(duration))
+	}
+	if channels > 0 {
+		add("Channels", fmt.Sprint(channels))
+	}
+	add("Languages", strings.Join(res.Transcription.Languages, ", "))
+	if res.Metadata.BillingTime > 0 {
+		add("Billing time", FormatClock(res.Metadata.BillingTime))
+	}
+	if res.Metadata.TranscriptionTime > 0 {
+		add("Transcription time", FormatClock(res.Metadata.TranscriptionTime))
+	}
+	return fields
+}
+
+// Якорь реплики в отчете для ссылок из глав
+func timeAnchor(sec float64) string {
+	return fmt.Sprintf("t-%d", int64(sec*1000))
+}
+
+// Записи транскрипта отчета: реплики спикеров или высказывания, если спикеры не определены
+func reportTurns(res *prerecorderv2.Result) []Turn {
+	if hasSpeakers(res) {
+		return Dialogue(res)
+	}
+	var turns []Turn
+	for _, u := range res.Transcription.Utterances {
+		if text := strings.TrimSpace(u.Text); text != "" {
+			turns = append(turns, Turn{Start: u.Start, End: u.End, Text: text})
+		}
+	}
+	return turns
+}
+
+// Начало реплики, в которую попадает момент времени, для ссылок из глав на транскрипт
+func turnAt(turns []Turn, sec float64) (float64, bool) {
+	for _, turn := range turns {
+		if turn.End > sec {
+			return turn.Start, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,44 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+func init() {
+	Register("sbv", FormatterFunc(formatSBV))
+}
+
+// YouTube SubViewer субтитры: "0:00:01.200,0:00:03.400" и строки текста
+func formatSBV(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	if resp == nil || resp.Result == nil {
+		return errNoResult
+	}
+
+	cues := Cues(resp.Result, opts)
+	if err := validateCues(cues); err != nil {
+		return fmt.Errorf("sbv: %w", err)
+	}
+
+	var b strings.Builder
+	for _, cue := range cues {
+		lines := cue.Lines
+		if label := speakerLabel(cue.Speaker, opts); label != "" {
+			lines = append([]string{label + ": " + lines[0]}, lines[1:]...)
+		}
+
+		// пустая строка разделяет субтитры, поэтому внутри субтитра ее быть не должно
+		fmt.Fprintf(&b, "%s,%s\n%s\n\n", sbvTimecode(cue.Start), sbvTimecode(cue.End), strings.Join(lines, "\n"))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Таймкод SBV: 0:00:00.000
+func sbvTimecode(sec float64) string {
+	return strings.TrimPrefix(formatTimecode(sec, "."), "0")
+}
@@ -0,0 +1,42 @@
+package output
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSBVGolden(t *testing.T) {
+	opts := Options{SpeakerLabels: true}
+	got, err := render(t, "sbv", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "sbv", got)
+
+	cues := Cues(loadResult(t).Result, opts)
+	blocks := strings.Split(strings.TrimSuffix(string(got), "\n\n"), "\n\n")
+	if len(blocks) != len(cues) {
+		t.Fatalf("got %d subtitles, want %d", len(blocks), len(cues))
+	}
+	for i, block := range blocks {
+		cue := cues[i]
+		timing, text, _ := strings.Cut(block, "\n")
+
+		var h1, m1, s1, ms1, h2, m2, s2, ms2 int64
+		if _, err := fmt.Sscanf(timing, "%d:%d:%d.%d,%d:%d:%d.%d", &h1, &m1, &s1, &ms1, &h2, &m2, &s2, &ms2); err != nil {
+			t.Fatalf("subtitle %d: timing %q: %s", i+1, timing, err)
+		}
+		start := float64((h1*60+m1)*60+s1) + float64(ms1)/1000
+		end := float64((h2*60+m2)*60+s2) + float64(ms2)/1000
+		if math.Abs(start-cue.Start) > 0.0005 || math.Abs(end-cue.End) > 0.0005 {
+			t.Errorf("subtitle %d: got %.3f-%.3f, want %.3f-%.3f", i+1, start, end, cue.Start, cue.End)
+		}
+
+		want := speakerLabel(cue.Speaker, opts) + ": " + strings.Join(cue.Lines, "\n")
+		if text != want {
+			t.Errorf("subtitle %d: got %q, want %q", i+1, text, want)
+		}
+	}
+}
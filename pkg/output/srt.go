@@ -0,0 +1,33 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+func init() {
+	Register("srt", FormatterFunc(formatSRT))
+}
+
+func formatSRT(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	if resp == nil || resp.Result == nil {
+		return errNoResult
+	}
+
+	var b strings.Builder
+	for idx, cue := range Cues(resp.Result, opts) {
+		lines := cue.Lines
+		if label := speakerLabel(cue.Speaker, opts); label != "" {
+			lines = append([]string{label + ": " + lines[0]}, lines[1:]...)
+		}
+
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n",
+			idx+1, formatTimecode(cue.Start, ","), formatTimecode(cue.End, ","), strings.Join(lines, "\n"))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
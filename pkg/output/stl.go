@@ -0,0 +1,325 @@
+package output
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Ограничения EBU Tech 3264 для телетекста
+const (
+	stlGSISize      = 1024
+	stlTTISize      = 128
+	stlTextSize     = 112
+	stlMaxRowLength = 40
+	stlMaxRows      = 23
+	stlMaxSubtitles = 1 << 16 // SN занимает два байта: номера 0..65535
+
+	stlLineBreak = 0x8A
+	stlUnused    = 0x8F
+)
+
+// Коды языков EBU Tech 3264 по iso639-1
+var stlLanguageCodes = map[string]string{
+	"en": "09", "de": "08", "es": "0A", "fr": "0F", "it": "15",
+	"nl": "1D", "pt": "21", "pl": "1E", "sv": "28", "ru": "56", "uk": "5D",
+}
+
+// Дата создания и изменения в GSI, в тестах подменяется фиксированной
+var stlNow = time.Now
+
+func init() {
+	Register("stl", FormatterFunc(formatSTL))
+}
+
+/*
+# EBU-STL субтитры (EBU Tech 3264)
+
+Бинарный файл: блок GSI с общей информацией и по одному блоку TTI на
+субтитр. Поддерживаются частоты 25 (STL25.01) и 30/29.97 (STL30.01),
+строки не длиннее 40 символов, текст субтитра не больше 112 байт.
+Текст кодируется в ISO 6937 (латиница) или ISO 8859-5 (кириллица).
+*/
+func formatSTL(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	if resp == nil || resp.Result == nil {
+		return errNoResult
+	}
+
+	fr := opts.FrameRate
+	if fr == nil {
+		fr = &FrameRate{Name: "25", Nominal: 25, Num: 25, Den: 1}
+	}
+	var dfc string
+	switch fr.Nominal {
+	case 25:
+		dfc = "STL25.01"
+	case 30:
+		dfc = "STL30.01"
+	default:
+		return fmt.Errorf("stl: unsupported frame rate %s, use 25, 29.97 or 30", fr.Name)
+	}
+
+	opts = opts.withDefaults()
+	if opts.MaxLineLength > stlMaxRowLength {
+		opts.MaxLineLength = stlMaxRowLength
+	}
+
+	// подпись спикера занимает место в первой строке, длинная подпись выносится в отдельную строку
+	labelWidth := stlLabelWidth(resp.Result, opts)
+	labelRow := labelWidth > stlMaxRowLength/2
+	if labelWidth > 0 && !labelRow {
+		opts.MaxLineLength = min(opts.MaxLineLength, stlMaxRowLength-labelWidth)
+	}
+
+	cues := Cues(resp.Result, opts)
+	if err := validateCues(cues); err != nil {
+		return fmt.Errorf("stl: %w", err)
+	}
+	if len(cues) > stlMaxSubtitles {
+		return fmt.Errorf("stl: too many subtitles %d, maximum %d", len(cues), stlMaxSubtitles)
+	}
+
+	cct := "00"
+	if hasCyrillic(cues) {
+		cct = "01"
+	}
+
+	var tti bytes.Buffer
+	for idx, cue := range cues {
+		lines := cue.Lines
+		if label := speakerLabel(cue.Speaker, opts); label != "" && labelRow {
+			lines = append([]string{label + ":"}, lines...)
+		} else if label != "" {
+			lines = append([]string{label + ": " + lines[0]}, lines[1:]...)
+		}
+		if len(lines) > stlMaxRows {
+			return fmt.Errorf("stl: subtitle %d: too many rows", idx+1)
+		}
+
+		var text []byte
+		for i, line := range lines {
+			if len([]rune(line)) > stlMaxRowLength {
+				return fmt.Errorf("stl: subtitle %d: row longer than %d characters", idx+1, stlMaxRowLength)
+			}
+			encoded, err := encodeSTLText(line, cct)
+			if err != nil {
+				return fmt.Errorf("stl: subtitle %d: %w", idx+1, err)
+			}
+			if i > 0 {
+				// строки телетекста идут через одну, поэтому перенос двойной
+				text = append(text, stlLineBreak, stlLineBreak)
+			}
+			text = append(text, encoded...)
+		}
+		if len(text) > stlTextSize {
+			return fmt.Errorf("stl: subtitle %d: text longer than %d bytes", idx+1, stlTextSize)
+		}
+
+		block := make([]byte, stlTTISize)
+		block[0] = 0                                           // SGN: номер группы
+		binary.LittleEndian.PutUint16(block[1:3], uint16(idx)) // SN: номер субтитра
+		block[3] = 0xFF                                        // EBN: последний блок субтитра
+		block[4] = 0                                           // CS: не кумулятивный
+		putSTLTimecode(block[5:9], *fr, cue.Start)             // TCI
+		putSTLTimecode(block[9:13], *fr, cue.End)              // TCO
+		block[13] = byte(stlMaxRows - 1 - 2*(len(lines)-1))    // VP: строки прижаты к низу экрана
+		block[14] = 2                                          // JC: по центру
+		block[15] = 0                                          // CF: субтитр, а не комментарий
+		copy(block[16:], bytes.Repeat([]byte{stlUnused}, stlTextSize))
+		copy(block[16:], text)
+
+		tti.Write(block)
+	}
+
+	lang := "00"
+	if langs := resp.Result.Transcription.Languages; len(langs) > 0 {
+		if code, ok := stlLanguageCodes[langs[0]]; ok {
+			lang = code
+		}
+	}
+
+	firstInCue := "00000000"
+	if len(cues) > 0 {
+		hh, mm, ss, ff := fr.Split(cues[0].Start)
+		firstInCue = fmt.Sprintf("%02d%02d%02d%02d", hh, mm, ss, ff)
+	}
+
+	title := ""
+	if resp.File != nil {
+		title = resp.File.Filename
+	}
+	today := stlNow().Format("060102")
+
+	gsi := newFieldWriter(stlGSISize)
+	gsi.put("850", 3)                                // CPN
+	gsi.put(dfc, 8)                                  // DFC
+	gsi.put("1", 1)                                  // DSC: телетекст уровня 1
+	gsi.put(cct, 2)                                  // CCT
+	gsi.put(lang, 2)                                 // LC
+	gsi.put(asciiOnly(title), 32)                    // OPT
+	gsi.put("", 32)                                  // OET
+	gsi.put("", 32)                                  // TPT
+	gsi.put("", 32)                                  // TET
+	gsi.put("", 32)                                  // TN
+	gsi.put("", 32)                                  // TCD
+	gsi.put(asciiOnly(resp.ID), 16)                  // SLR
+	gsi.put(today, 6)                                // CD
+	gsi.put(today, 6)                                // RD
+	gsi.put("00", 2)                                 // RN
+	gsi.put(fmt.Sprintf("%05d", len(cues)), 5)       // TNB
+	gsi.put(fmt.Sprintf("%05d", len(cues)), 5)       // TNS
+	gsi.put("001", 3)                                // TNG
+	gsi.put(fmt.Sprintf("%02d", stlMaxRowLength), 2) // MNC
+	gsi.put(fmt.Sprintf("%02d", stlMaxRows), 2)      // MNR
+	gsi.put("1", 1)                                  // TCS
+	gsi.put("00000000", 8)                           // TCP
+	gsi.put(firstInCue, 8)                           // TCF
+	gsi.put("1", 1)                                  // TND
+	gsi.put("1", 1)                                  // DSN
+	gsi.put("", 3)                                   // CO
+	gsi.put("", 32)                                  // PUB
+	gsi.put("", 32)                                  // EN
+	gsi.put("", 32)                                  // ECD
+	gsi.put("", 75)                                  // Spare
+	gsi.put("", 576)                                 // UDA
+
+	if _, err := w.Write(gsi.bytes()); err != nil {
+		return err
+	}
+	_, err := w.Write(tti.Bytes())
+	return err
+}
+
+// Ширина самой длинной подписи спикера вместе с ": ", 0 - подписей нет
+func stlLabelWidth(res *prerecorderv2.Result, opts Options) int {
+	width := 0
+	for _, u := range res.Transcription.Utterances {
+		if label := speakerLabel(u.Speaker, opts); label != "" {
+			width = max(width, len([]rune(label))+2)
+		}
+	}
+	return width
+}
+
+// Поля GSI заполняются пробелами до фиксированной длины
+type fieldWriter struct {
+	buf []byte
+}
+
+func newFieldWriter(size int) *fieldWriter {
+	return &fieldWriter{buf: make([]byte, 0, size)}
+}
+
+func (f *fieldWriter) put(value string, size int) {
+	field := bytes.Repeat([]byte{' '}, size)
+	copy(field, value)
+	f.buf = append(f.buf, field...)
+}
+
+func (f *fieldWriter) bytes() []byte {
+	return f.buf
+}
+
+// Таймкод TTI: четыре байта часы, минуты, секунды, кадры
+func putSTLTimecode(dst []byte, fr FrameRate, sec float64) {
+	hh, mm, ss, ff := fr.Split(sec)
+	dst[0], dst[1], dst[2], dst[3] = byte(hh), byte(mm), byte(ss), byte(ff)
+}
+
+func hasCyrillic(cues []Cue) bool {
+	for _, cue := range cues {
+		for _, r := range cue.Text() {
+			if unicode.Is(unicode.Cyrillic, r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func asciiOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7E {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// Символы ISO 6937 с диакритикой: байт знака и базовая буква
+var iso6937Diacritics = map[byte][2]string{
+	0xC1: {"ÀÈÌÒÙàèìòù", "AEIOUaeiou"},
+	0xC2: {"ÁÉÍÓÚÝáéíóúý", "AEIOUYaeiouy"},
+	0xC3: {"ÂÊÎÔÛâêîôû", "AEIOUaeiou"},
+	0xC4: {"ÃÑÕãñõ", "ANOano"},
+	0xC8: {"ÄËÏÖÜäëïöüÿ", "AEIOUaeiouy"},
+	0xCA: {"Åå", "Aa"},
+	0xCB: {"Çç", "Cc"},
+}
+
+// Символы ISO 6937, кодируемые одним байтом
+var iso6937Single = map[rune]byte{
+	'¡': 0xA1, '£': 0xA3, '«': 0xAB, '»': 0xBB, '¿': 0xBF,
+	'Æ': 0xE1, 'Ð': 0xE2, 'Ø': 0xE9, 'Œ': 0xEA, 'Þ': 0xEC,
+	'æ': 0xF1, 'ð': 0xF3, 'ø': 0xF9, 'œ': 0xFA, 'ß': 0xFB, 'þ': 0xFC,
+}
+
+func encodeSTLText(s string, cct string) ([]byte, error) {
+	var out []byte
+	for _, r := range s {
+		switch {
+		case r >= 0x20 && r <= 0x7E:
+			out = append(out, byte(r))
+		case cct == "01":
+			b, ok := iso8859_5(r)
+			if !ok {
+				return nil, fmt.Errorf("character %q is not supported by the Latin/Cyrillic code table", r)
+			}
+			out = append(out, b)
+		default:
+			b, ok := iso6937(r)
+			if !ok {
+				return nil, fmt.Errorf("character %q is not supported by the Latin code table", r)
+			}
+			out = append(out, b...)
+		}
+	}
+	return out, nil
+}
+
+func iso6937(r rune) ([]byte, bool) {
+	if b, ok := iso6937Single[r]; ok {
+		return []byte{b}, true
+	}
+	for mark, pair := range iso6937Diacritics {
+		composed, bases := []rune(pair[0]), pair[1]
+		for i, c := range composed {
+			if c == r {
+				return []byte{mark, bases[i]}, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func iso8859_5(r rune) (byte, bool) {
+	switch {
+	case r >= 0x0401 && r <= 0x040C:
+		return byte(r-0x0401) + 0xA1, true
+	case r >= 0x040E && r <= 0x044F:
+		return byte(r-0x040E) + 0xAE, true
+	case r >= 0x0451 && r <= 0x045C:
+		return byte(r-0x0451) + 0xF1, true
+	case r == 0x045E || r == 0x045F:
+		return byte(r-0x045E) + 0xFE, true
+	case r == '№':
+		return 0xF0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,133 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+func TestSTLGolden(t *testing.T) {
+	stlNow = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	t.Cleanup(func() { stlNow = time.Now })
+
+	for _, name := range goldenFrameRates {
+		t.Run(name, func(t *testing.T) {
+			opts := Options{SpeakerLabels: true, FrameRate: frameRate(t, name)}
+			got, err := render(t, "stl", opts)
+			if opts.FrameRate.Nominal != 25 && opts.FrameRate.Nominal != 30 {
+				// у EBU-STL нет кода для 23.976, такой файл не должен создаваться
+				if err == nil {
+					t.Fatalf("expected unsupported frame rate error for %s", name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, "stl_"+name, got)
+			checkSTL(t, got, opts)
+		})
+	}
+}
+
+// Разобрать GSI и блоки TTI и сверить таймкоды и текст с исходными субтитрами
+func checkSTL(t *testing.T, data []byte, opts Options) {
+	t.Helper()
+	fr := *opts.FrameRate
+	cues := Cues(loadResult(t).Result, opts)
+
+	if len(data) != stlGSISize+len(cues)*stlTTISize {
+		t.Fatalf("got %d bytes, want GSI and %d TTI blocks", len(data), len(cues))
+	}
+	gsi := data[:stlGSISize]
+	if dfc := string(gsi[3:11]); dfc != map[int]string{25: "STL25.01", 30: "STL30.01"}[fr.Nominal] {
+		t.Errorf("unexpected DFC %q for %s fps", dfc, fr.Name)
+	}
+	if tnb, want := string(gsi[238:243]), fmt.Sprintf("%05d", len(cues)); tnb != want {
+		t.Errorf("TNB %q, want %s", tnb, want)
+	}
+
+	for i, cue := range cues {
+		block := data[stlGSISize+i*stlTTISize:][:stlTTISize]
+		if sn := int(block[1]) | int(block[2])<<8; sn != i {
+			t.Errorf("block %d: subtitle number %d", i, sn)
+		}
+		for _, tc := range []struct {
+			what  string
+			bytes []byte
+			want  float64
+		}{
+			{"TCI", block[5:9], cue.Start},
+			{"TCO", block[9:13], cue.End},
+		} {
+			hh, mm, ss, ff := int64(tc.bytes[0]), int64(tc.bytes[1]), int64(tc.bytes[2]), int64(tc.bytes[3])
+			if ff >= int64(fr.Nominal) {
+				t.Errorf("block %d %s: frame %d out of range", i, tc.what, ff)
+			}
+			checkFrameTime(t, tc.what, fr, timecodeFrames(fr, hh, mm, ss, ff), tc.want)
+		}
+
+		text := block[16:]
+		if end := bytes.IndexByte(text, stlUnused); end >= 0 {
+			text = text[:end]
+		}
+		want, err := encodeSTLText(speakerLabel(cue.Speaker, opts)+": "+strings.Join(cue.Lines, " "), "00")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := bytes.ReplaceAll(text, []byte{stlLineBreak, stlLineBreak}, []byte(" ")); !bytes.Equal(got, want) {
+			t.Errorf("block %d: text %q, want %q", i, got, want)
+		}
+	}
+}
+
+// Подпись спикера не должна выводить заполненную первую строку за 40 символов
+func TestSTLSpeakerLabelFitsRow(t *testing.T) {
+	speaker := 0
+	var words []prerecorderv2.Word
+	for i, w := range strings.Fields("the quarterly report shows that revenue grew faster than expected in every region we operate in") {
+		words = append(words, prerecorderv2.Word{Word: " " + w, Start: float64(i) * 0.4, End: float64(i)*0.4 + 0.3, Confidence: 0.9})
+	}
+	resp := &prerecorderv2.PreRecorderResultResponse{ID: "t1", Result: &prerecorderv2.Result{}}
+	resp.Result.Transcription.Utterances = []prerecorderv2.Utterance{{
+		Start: 0, End: words[len(words)-1].End, Speaker: &speaker, Words: words,
+	}}
+
+	for _, tc := range []struct {
+		name     string
+		opts     Options
+		labelRow bool
+	}{
+		{"label in the first row", Options{SpeakerLabels: true}, false},
+		{"long name in its own row", Options{SpeakerNames: map[int]string{0: "Dr. Maximilian Fischer-Hoffmann"}}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := formatSTL(&b, resp, tc.opts); err != nil {
+				t.Fatal(err)
+			}
+
+			label := SpeakerName(&speaker, tc.opts) + ":"
+			blocks := (b.Len() - stlGSISize) / stlTTISize
+			for i := range blocks {
+				text := b.Bytes()[stlGSISize+i*stlTTISize+16:][:stlTextSize]
+				if end := bytes.IndexByte(text, stlUnused); end >= 0 {
+					text = text[:end]
+				}
+				rows := strings.Split(string(text), string([]byte{stlLineBreak, stlLineBreak}))
+				for _, row := range rows {
+					if len(row) > stlMaxRowLength {
+						t.Errorf("block %d: row %q longer than %d characters", i, row, stlMaxRowLength)
+					}
+				}
+				if tc.labelRow && rows[0] != label || !tc.labelRow && !strings.HasPrefix(rows[0], label+" ") {
+					t.Errorf("block %d: first row %q, want the label %q", i, rows[0], label)
+				}
+			}
+		})
+	}
+}
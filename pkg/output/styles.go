@@ -0,0 +1,207 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Стиль субтитров SubStation Alpha. Цвета в формате #RRGGBB или #RRGGBBAA, где AA - непрозрачность
+type SubStationStyle struct {
+	Font            string  `yaml:"font"`
+	Size            int     `yaml:"size"`
+	Colour          string  `yaml:"colour"`
+	SecondaryColour string  `yaml:"secondary_colour"` // цвет еще не произнесенных слов в режиме караоке
+	OutlineColour   string  `yaml:"outline_colour"`
+	BackColour      string  `yaml:"back_colour"`
+	Bold            bool    `yaml:"bold"`
+	Italic          bool    `yaml:"italic"`
+	Outline         float64 `yaml:"outline"`
+	Shadow          float64 `yaml:"shadow"`
+	Alignment       int     `yaml:"alignment"` // как на цифровой клавиатуре: 2 - снизу по центру, 8 - сверху по центру
+	MarginL         int     `yaml:"margin_l"`
+	MarginR         int     `yaml:"margin_r"`
+	MarginV         int     `yaml:"margin_v"`
+}
+
+/*
+# Таблица стилей ASS/SSA субтитров
+
+Стили спикеров дополняют стиль по умолчанию, поэтому в них достаточно указать
+отличающиеся поля:
+
+	resolution:
+	  width: 1920
+	  height: 1080
+	default:
+	  font: Arial
+	  size: 64
+	  colour: "#FFFFFF"
+	  margin_v: 50
+	speakers:
+	  0:
+	    colour: "#FFD700"
+	  1:
+	    colour: "#00BFFF"
+*/
+type StyleSheet struct {
+	Resolution struct {
+		Width  int `yaml:"width"`
+		Height int `yaml:"height"`
+	} `yaml:"resolution"`
+	Default  SubStationStyle         `yaml:"default"`
+	Speakers map[int]SubStationStyle `yaml:"speakers"`
+}
+
+func DefaultStyleSheet() *StyleSheet {
+	sheet := &StyleSheet{
+		Default: SubStationStyle{
+			Font:            "Arial",
+			Size:            64,
+			Colour:          "#FFFFFF",
+			SecondaryColour: "#FFFF00",
+			OutlineColour:   "#000000",
+			BackColour:      "#00000080",
+			Outline:         3,
+			Alignment:       2,
+			MarginL:         60,
+			MarginR:         60,
+			MarginV:         50,
+		},
+	}
+	sheet.Resolution.Width = 1920
+	sheet.Resolution.Height = 1080
+	return sheet
+}
+
+// Загрузить таблицу стилей из yaml файла поверх стилей по умолчанию
+func LoadStyleSheet(filePath string) (*StyleSheet, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed read style sheet: %w", filePath, err)
+	}
+
+	sheet := DefaultStyleSheet()
+	if err := yaml.Unmarshal(data, sheet); err != nil {
+		return nil, fmt.Errorf("%s: failed parse style sheet: %w", filePath, err)
+	}
+
+	if err := sheet.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return sheet, nil
+}
+
+func (s *StyleSheet) validate() error {
+	if s.Resolution.Width <= 0 || s.Resolution.Height <= 0 {
+		return fmt.Errorf("invalid resolution %dx%d", s.Resolution.Width, s.Resolution.Height)
+	}
+
+	styles := map[string]SubStationStyle{"default": s.Default}
+	for id := range s.Speakers {
+		styles[fmt.Sprintf("speaker %d", id)] = s.speakerStyle(id)
+	}
+
+	for name, st := range styles {
+		if st.Font == "" || st.Size <= 0 {
+			return fmt.Errorf("%s style: font and size are required", name)
+		}
+		if st.Alignment < 1 || st.Alignment > 9 {
+			return fmt.Errorf("%s style: alignment must be between 1 and 9", name)
+		}
+		for _, colour := range []string{st.Colour, st.SecondaryColour, st.OutlineColour, st.BackColour} {
+			if _, _, err := parseColour(colour); err != nil {
+				return fmt.Errorf("%s style: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Стиль спикера: стиль по умолчанию, дополненный заданными для спикера полями
+func (s *StyleSheet) speakerStyle(speaker int) SubStationStyle {
+	st := s.Default
+	override, ok := s.Speakers[speaker]
+	if !ok {
+		return st
+	}
+
+	if override.Font != "" {
+		st.Font = override.Font
+	}
+	if override.Size > 0 {
+		st.Size = override.Size
+	}
+	if override.Colour != "" {
+		st.Colour = override.Colour
+	}
+	if override.SecondaryColour != "" {
+		st.SecondaryColour = override.SecondaryColour
+	}
+	if override.OutlineColour != "" {
+		st.OutlineColour = override.OutlineColour
+	}
+	if override.BackColour != "" {
+		st.BackColour = override.BackColour
+	}
+	if override.Bold {
+		st.Bold = true
+	}
+	if override.Italic {
+		st.Italic = true
+	}
+	if override.Outline > 0 {
+		st.Outline = override.Outline
+	}
+	if override.Shadow > 0 {
+		st.Shadow = override.Shadow
+	}
+	if override.Alignment > 0 {
+		st.Alignment = override.Alignment
+	}
+	if override.MarginL > 0 {
+		st.MarginL = override.MarginL
+	}
+	if override.MarginR > 0 {
+		st.MarginR = override.MarginR
+	}
+	if override.MarginV > 0 {
+		st.MarginV = override.MarginV
+	}
+	return st
+}
+
+// Цвет ASS: &HAABBGGRR, где AA - прозрачность
+func assColour(colour string) string {
+	rgb, alpha, _ := parseColour(colour)
+	return fmt.Sprintf("&H%02X%02X%02X%02X", 255-alpha, rgb[2], rgb[1], rgb[0])
+}
+
+// Цвет SSA: &HBBGGRR, прозрачность задается отдельно
+func ssaColour(colour string) string {
+	rgb, _, _ := parseColour(colour)
+	return fmt.Sprintf("&H%02X%02X%02X", rgb[2], rgb[1], rgb[0])
+}
+
+// Разобрать #RRGGBB или #RRGGBBAA
+func parseColour(colour string) ([3]uint8, uint8, error) {
+	hex := strings.TrimPrefix(colour, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return [3]uint8{}, 0, fmt.Errorf("invalid colour %q, expected #RRGGBB or #RRGGBBAA", colour)
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return [3]uint8{}, 0, fmt.Errorf("invalid colour %q: %w", colour, err)
+	}
+
+	alpha := uint8(255)
+	if len(hex) == 8 {
+		alpha = uint8(value)
+		value >>= 8
+	}
+	return [3]uint8{uint8(value >> 16), uint8(value >> 8), uint8(value)}, alpha, nil
+}
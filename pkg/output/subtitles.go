@@ -0,0 +1,180 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Значения по умолчанию для нарезки субтитров
+const (
+	defaultMaxLineLength  = 42
+	defaultMaxLines       = 2
+	defaultMaxCueDuration = 7.0
+)
+
+// Один субтитр
+type Cue struct {
+	Start   float64
+	End     float64
+	Speaker *int
+	Lines   []string
+	Words   []prerecorderv2.Word // слова субтитра, если в ответе есть пословные таймкоды
+	// строки перевода в двуязычных субтитрах, входят в Lines после строк оригинала
+	Translation []string
+
+	breaks []int // индексы Words, с которых начинаются строки со второй
+}
+
+// Слова субтитра, разбитые по строкам
+func (c Cue) WordLines() [][]prerecorderv2.Word {
+	lines := make([][]prerecorderv2.Word, 0, len(c.breaks)+1)
+	start := 0
+	for _, idx := range c.breaks {
+		lines = append(lines, c.Words[start:idx])
+		start = idx
+	}
+	return append(lines, c.Words[start:])
+}
+
+// Текст субтитра одной строкой
+func (c Cue) Text() string {
+	return strings.Join(c.Lines, " ")
+}
+
+/*
+# Нарезать высказывания на субтитры
+
+Общие правила для всех форматов субтитров: субтитр не выходит за границы
+высказывания, содержит не более MaxLines строк по MaxLineLength символов
+и длится не дольше MaxCueDuration секунд. Если в ответе нет пословных
+таймкодов, время высказывания делится между словами поровну. С
+Options.Bilingual в субтитры добавляется перевод.
+*/
+func Cues(res *prerecorderv2.Result, opts Options) []Cue {
+	opts = opts.withDefaults()
+	if opts.Bilingual != "" {
+		return bilingualCues(res, opts)
+	}
+	return monolingualCues(res, opts)
+}
+
+func monolingualCues(res *prerecorderv2.Result, opts Options) []Cue {
+	var cues []Cue
+	for _, u := range res.Transcription.Utterances {
+		words := u.Words
+		if len(words) == 0 {
+			words = spreadWords(u)
+		}
+		cues = append(cues, splitUtterance(u, words, opts)...)
+	}
+	return cues
+}
+
+func splitUtterance(u prerecorderv2.Utterance, words []prerecorderv2.Word, opts Options) []Cue {
+	var cues []Cue
+	var cur *Cue
+	line := ""
+
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		if line != "" {
+			cur.Lines = append(cur.Lines, line)
+		}
+		cues = append(cues, *cur)
+		cur, line = nil, ""
+	}
+
+	for _, w := range words {
+		text := strings.TrimSpace(w.Word)
+		if text == "" {
+			continue
+		}
+
+		if cur != nil {
+			fits := len([]rune(line))+1+len([]rune(text)) <= opts.MaxLineLength
+			switch {
+			case w.End-cur.Start > opts.MaxCueDuration:
+				flush()
+			case !fits && len(cur.Lines)+1 >= opts.MaxLines:
+				flush()
+			case !fits:
+				cur.Lines = append(cur.Lines, line)
+				cur.breaks = append(cur.breaks, len(cur.Words))
+				line = ""
+			}
+		}
+
+		if cur == nil {
+			cur = &Cue{Start: w.Start, Speaker: u.Speaker}
+		}
+		if line == "" {
+			line = text
+		} else {
+			line += " " + text
+		}
+		cur.End = w.End
+		cur.Words = append(cur.Words, w)
+	}
+	flush()
+
+	return cues
+}
+
+// Пословные таймкоды из текста высказывания, если API их не вернул
+func spreadWords(u prerecorderv2.Utterance) []prerecorderv2.Word {
+	fields := strings.Fields(u.Text)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	step := (u.End - u.Start) / float64(len(fields))
+	words := make([]prerecorderv2.Word, 0, len(fields))
+	for i, field := range fields {
+		words = append(words, prerecorderv2.Word{
+			Word:       field,
+			Start:      u.Start + step*float64(i),
+			End:        u.Start + step*float64(i+1),
+			Confidence: u.Confidence,
+		})
+	}
+	return words
+}
+
+// Подпись спикера для субтитра, пустая если подписи выключены или спикер не определен
+func speakerLabel(speaker *int, opts Options) string {
+	if !opts.SpeakerLabels && len(opts.SpeakerNames) == 0 {
+		return ""
+	}
+	return SpeakerName(speaker, opts)
+}
+
+// Таймкод 00:00:00,000 (sep - разделитель миллисекунд)
+func formatTimecode(sec float64, sep string) string {
+	if sec < 0 {
+		sec = 0
+	}
+	ms := int64(sec*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms%3600000/60000, ms%60000/1000, sep, ms%1000)
+}
+
+// Общие ограничения форматов субтитров: субтитр не пустой, конец позже начала, субтитры идут по порядку
+func validateCues(cues []Cue) error {
+	prevStart := 0.0
+	for idx, cue := range cues {
+		if len(cue.Lines) == 0 {
+			return fmt.Errorf("subtitle %d: empty text", idx+1)
+		}
+		if cue.End <= cue.Start {
+			return fmt.Errorf("subtitle %d: end %s is not after start %s", idx+1, formatTimecode(cue.End, "."), formatTimecode(cue.Start, "."))
+		}
+		if cue.Start < prevStart {
+			return fmt.Errorf("subtitle %d: starts before the previous subtitle", idx+1)
+		}
+		prevStart = cue.Start
+	}
+	return nil
+}
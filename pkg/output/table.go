@@ -0,0 +1,252 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Гранулярность строк табличных форматов
+const (
+	RowsUtterance = "utterance" // строка на высказывание
+	RowsWord      = "word"      // строка на слово
+)
+
+// Колонки csv/tsv, в jsonl те же ключи
+var tableColumns = []string{
+	"task_id", "file_name", "channel", "speaker", "speaker_name", "language",
+	"start", "end", "confidence", "text", "sentiment", "emotion",
+}
+
+func init() {
+	Register("csv", tableFormatter("csv"))
+	Register("tsv", tableFormatter("tsv"))
+	Register("jsonl", tableFormatter("jsonl"))
+}
+
+// Строка табличного экспорта
+type TableRow struct {
+	TaskID      string  `json:"task_id"`
+	FileName    string  `json:"file_name"`
+	Channel     int     `json:"channel"`
+	Speaker     *int    `json:"speaker"`
+	SpeakerName string  `json:"speaker_name,omitempty"`
+	Language    string  `json:"language"`
+	Start       float64 `json:"start"`
+	End         float64 `json:"end"`
+	Confidence  float64 `json:"confidence"`
+	Text        string  `json:"text"`
+	Sentiment   string  `json:"sentiment,omitempty"`
+	Emotion     string  `json:"emotion,omitempty"`
+}
+
+/*
+# Потоковая запись табличных форматов
+
+Один TableWriter пишет строки нескольких результатов в один файл:
+заголовок csv/tsv выводится один раз, колонки у всех результатов общие,
+поэтому файл пакета сразу читается в pandas или загружается в BigQuery.
+*/
+type TableWriter struct {
+	format string
+	opts   Options
+	csv    *csv.Writer
+	json   *json.Encoder
+	header bool
+}
+
+// Является ли формат табличным, то есть поддерживает запись нескольких результатов в один файл
+func IsTable(format string) bool {
+	switch strings.ToLower(format) {
+	case "csv", "tsv", "jsonl":
+		return true
+	}
+	return false
+}
+
+func NewTableWriter(w io.Writer, format string, opts Options) (*TableWriter, error) {
+	tw := &TableWriter{format: strings.ToLower(format), opts: opts}
+
+	switch tw.format {
+	case "csv":
+		tw.csv = csv.NewWriter(w)
+	case "tsv":
+		tw.csv = csv.NewWriter(w)
+		tw.csv.Comma = '\t'
+	case "jsonl":
+		tw.json = json.NewEncoder(w)
+		tw.json.SetEscapeHTML(false)
+	default:
+		return nil, fmt.Errorf("format %q is not a table format, available: csv, tsv, jsonl", format)
+	}
+
+	switch opts.Rows {
+	case "", RowsUtterance, RowsWord:
+	default:
+		return nil, fmt.Errorf("unknown rows %q, available: %s, %s", opts.Rows, RowsUtterance, RowsWord)
+	}
+
+	return tw, nil
+}
+
+// Записать строки одного результата
+func (tw *TableWriter) Write(resp *prerecorderv2.PreRecorderResultResponse) error {
+	if resp == nil || resp.Result == nil {
+		return errNoResult
+	}
+
+	resp, opts := ByChannel(resp, tw.opts)
+	for _, row := range Rows(resp, opts) {
+		if err := tw.writeRow(row); err != nil {
+			return err
+		}
+	}
+
+	if tw.csv != nil {
+		tw.csv.Flush()
+		return tw.csv.Error()
+	}
+	return nil
+}
+
+// Записать заголовок csv/tsv, даже если строк не было
+func (tw *TableWriter) Close() error {
+	if tw.csv == nil {
+		return nil
+	}
+	if err := tw.writeHeader(); err != nil {
+		return err
+	}
+	tw.csv.Flush()
+	return tw.csv.Error()
+}
+
+func (tw *TableWriter) writeHeader() error {
+	if tw.header {
+		return nil
+	}
+	tw.header = true
+	return tw.csv.Write(tableColumns)
+}
+
+func (tw *TableWriter) writeRow(row TableRow) error {
+	if tw.json != nil {
+		return tw.json.Encode(row)
+	}
+
+	if err := tw.writeHeader(); err != nil {
+		return err
+	}
+
+	speaker := ""
+	if row.Speaker != nil {
+		speaker = strconv.Itoa(*row.Speaker)
+	}
+	return tw.csv.Write([]string{
+		row.TaskID,
+		tableCell(row.FileName),
+		strconv.Itoa(row.Channel),
+		speaker,
+		tableCell(row.SpeakerName),
+		row.Language,
+		strconv.FormatFloat(row.Start, 'f', 3, 64),
+		strconv.FormatFloat(row.End, 'f', 3, 64),
+		strconv.FormatFloat(row.Confidence, 'f', -1, 64),
+		tableCell(row.Text),
+		row.Sentiment,
+		row.Emotion,
+	})
+}
+
+// Табуляции и переводы строк в ячейке ломают tsv, заменяем их пробелами
+func tableCell(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+/*
+# Строки табличного экспорта
+
+По высказыванию или по слову в зависимости от Options.Rows. Слова
+наследуют канал, спикера, язык и тональность своего высказывания.
+*/
+func Rows(resp *prerecorderv2.PreRecorderResultResponse, opts Options) []TableRow {
+	if resp == nil || resp.Result == nil {
+		return nil
+	}
+
+	fileName := ""
+	if resp.File != nil {
+		fileName = resp.File.Filename
+	}
+	sentiments := resp.Result.SentimentAnalysis.Results
+
+	var rows []TableRow
+	for _, u := range resp.Result.Transcription.Utterances {
+		base := TableRow{
+			TaskID:   resp.ID,
+			FileName: fileName,
+			Channel:  u.Channel,
+			Speaker:  u.Speaker,
+			Language: u.Language,
+		}
+		if len(opts.SpeakerNames) > 0 {
+			base.SpeakerName = SpeakerName(u.Speaker, opts)
+		}
+		if s, ok := matchSentiment(sentiments, u); ok {
+			base.Sentiment, base.Emotion = s.Sentiment, s.Emotion
+		}
+
+		if opts.Rows != RowsWord {
+			row := base
+			row.Start, row.End, row.Confidence, row.Text = u.Start, u.End, u.Confidence, strings.TrimSpace(u.Text)
+			rows = append(rows, row)
+			continue
+		}
+
+		words := u.Words
+		if len(words) == 0 {
+			words = spreadWords(u)
+		}
+		for _, w := range words {
+			row := base
+			row.Start, row.End, row.Confidence, row.Text = w.Start, w.End, w.Confidence, strings.TrimSpace(w.Word)
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Фрагмент тональности с наибольшим пересечением с высказыванием на том же канале
+func matchSentiment(items []prerecorderv2.Sentiment, u prerecorderv2.Utterance) (prerecorderv2.Sentiment, bool) {
+	var best prerecorderv2.Sentiment
+	bestOverlap := 0.0
+	for _, item := range items {
+		if item.Channel != u.Channel {
+			continue
+		}
+		overlap := min(item.End, u.End) - max(item.Start, u.Start)
+		if overlap > bestOverlap {
+			best, bestOverlap = item, overlap
+		}
+	}
+	return best, bestOverlap > 0
+}
+
+// Формат для одного результата поверх TableWriter
+func tableFormatter(format string) Formatter {
+	return FormatterFunc(func(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+		tw, err := NewTableWriter(w, format, opts)
+		if err != nil {
+			return err
+		}
+		if err := tw.Write(resp); err != nil {
+			return err
+		}
+		return tw.Close()
+	})
+}
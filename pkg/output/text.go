@@ -0,0 +1,58 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+var errNoResult = errors.New("transcription result is empty")
+
+func init() {
+	Register("txt", FormatterFunc(formatText))
+}
+
+// Простой текст: транскрибация и дополнительные разделы, если они были запрошены
+func formatText(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	if resp == nil || resp.Result == nil {
+		return errNoResult
+	}
+	res := resp.Result
+
+	var b strings.Builder
+	if hasSpeakers(res) {
+		for _, turn := range Dialogue(res) {
+			fmt.Fprintf(&b, "[%s] %s: %s\n", FormatClock(turn.Start), SpeakerName(turn.Speaker, opts), turn.Text)
+		}
+	} else {
+		b.WriteString(res.Transcription.FullTranscript)
+		b.WriteString("\n")
+	}
+
+	if summary := res.Summarization.Results; summary != "" {
+		fmt.Fprintf(&b, "\nSummary\n-------\n%s\n", summary)
+	}
+
+	if chapters := res.Chapterization.Results; len(chapters) > 0 {
+		b.WriteString("\nChapters\n--------\n")
+		for _, ch := range chapters {
+			fmt.Fprintf(&b, "[%s - %s] %s\n", FormatClock(ch.Start), FormatClock(ch.End), ch.Headline)
+			if ch.Summary != "" {
+				fmt.Fprintf(&b, "  %s\n", ch.Summary)
+			}
+		}
+	}
+
+	if items := res.AudioToLLM.Results; len(items) > 0 {
+		b.WriteString("\nPrompts\n-------\n")
+		for _, item := range items {
+			fmt.Fprintf(&b, "> %s\n%s\n\n", item.Results.Prompt, item.Results.Response)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
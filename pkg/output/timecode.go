@@ -0,0 +1,81 @@
+package output
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Частота кадров для форматов с таймкодами в кадрах (TTML smpte, EBU-STL)
+type FrameRate struct {
+	Name    string
+	Nominal int  // кадров в секунде таймкода
+	Num     int  // реальная частота Num/Den
+	Den     int  //
+	Drop    bool // drop-frame таймкод (29.97)
+}
+
+var frameRates = map[string]FrameRate{
+	"23.976":   {Name: "23.976", Nominal: 24, Num: 24000, Den: 1001},
+	"24":       {Name: "24", Nominal: 24, Num: 24, Den: 1},
+	"25":       {Name: "25", Nominal: 25, Num: 25, Den: 1},
+	"29.97":    {Name: "29.97", Nominal: 30, Num: 30000, Den: 1001, Drop: true},
+	"29.97ndf": {Name: "29.97ndf", Nominal: 30, Num: 30000, Den: 1001},
+	"30":       {Name: "30", Nominal: 30, Num: 30, Den: 1},
+}
+
+// Разобрать частоту кадров: 23.976, 24, 25, 29.97 (drop-frame), 29.97ndf, 30
+func ParseFrameRate(value string) (*FrameRate, error) {
+	if value == "" {
+		return nil, nil
+	}
+	fr, ok := frameRates[strings.ToLower(value)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported frame rate %q, available: 23.976, 24, 25, 29.97, 29.97ndf, 30", value)
+	}
+	return &fr, nil
+}
+
+// Номер кадра, на который приходится момент времени
+func (fr FrameRate) Frames(sec float64) int64 {
+	if sec < 0 {
+		sec = 0
+	}
+	return int64(math.Round(sec * float64(fr.Num) / float64(fr.Den)))
+}
+
+// Таймкод HH:MM:SS:FF, для drop-frame разделитель кадров ";"
+func (fr FrameRate) Timecode(sec float64) string {
+	hh, mm, ss, ff := fr.Split(sec)
+	sep := ":"
+	if fr.Drop {
+		sep = ";"
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", hh, mm, ss, sep, ff)
+}
+
+// Компоненты таймкода. В drop-frame режиме номера кадров 0 и 1 пропускаются
+// в начале каждой минуты, кроме каждой десятой, чтобы таймкод не отставал от реального времени
+func (fr FrameRate) Split(sec float64) (hh, mm, ss, ff int64) {
+	frames := fr.Frames(sec)
+	nominal := int64(fr.Nominal)
+
+	if fr.Drop {
+		const dropPerMinute = 2
+		framesPer10Min := int64(math.Round(600 * float64(fr.Num) / float64(fr.Den)))
+		framesPerMin := nominal*60 - dropPerMinute
+
+		d := frames / framesPer10Min
+		m := frames % framesPer10Min
+		frames += 9 * dropPerMinute * d
+		if m > dropPerMinute {
+			frames += dropPerMinute * ((m - dropPerMinute) / framesPerMin)
+		}
+	}
+
+	ff = frames % nominal
+	ss = frames / nominal % 60
+	mm = frames / (nominal * 60) % 60
+	hh = frames / (nominal * 3600)
+	return hh, mm, ss, ff
+}
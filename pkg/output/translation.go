@@ -0,0 +1,189 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Языки переводов в результате в порядке ответа API
+func TranslationLanguages(res *prerecorderv2.Result) []string {
+	if res == nil {
+		return nil
+	}
+	var langs []string
+	for _, t := range res.Translation.Results {
+		langs = append(langs, t.Languages...)
+	}
+	return langs
+}
+
+// Перевод на язык lang
+func Translation(res *prerecorderv2.Result, lang string) (prerecorderv2.Transcription, error) {
+	if res != nil {
+		for _, t := range res.Translation.Results {
+			for _, l := range t.Languages {
+				if strings.EqualFold(l, lang) {
+					return t, nil
+				}
+			}
+		}
+	}
+
+	available := strings.Join(TranslationLanguages(res), ", ")
+	if available == "" {
+		available = "none, start the task with translation"
+	}
+	return prerecorderv2.Transcription{}, fmt.Errorf("no %q translation in the result, available: %s", lang, available)
+}
+
+/*
+# Результат на языке перевода
+
+Транскрипция заменяется переводом, поэтому любой формат вывода пишет
+переведенный текст с таймкодами и спикерами перевода. Исходный ответ
+не меняется.
+*/
+func Translated(resp *prerecorderv2.PreRecorderResultResponse, lang string) (*prerecorderv2.PreRecorderResultResponse, error) {
+	if resp == nil || resp.Result == nil {
+		return nil, errNoResult
+	}
+	t, err := Translation(resp.Result, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	res := *resp.Result
+	res.Transcription = t
+	res.Translation = prerecorderv2.TaskResult{}
+
+	out := *resp
+	out.Result = &res
+	return &out, nil
+}
+
+// Файл перевода рядом с основным: result.srt -> result.fr.srt
+func TranslationPath(filePath, lang string) string {
+	ext := filepath.Ext(filePath)
+	return strings.TrimSuffix(filePath, ext) + "." + strings.ToLower(lang) + ext
+}
+
+/*
+# Двуязычные субтитры
+
+Оригинал нарезается на субтитры как обычно, а перевод высказывания
+делится между его субтитрами пропорционально количеству слов и выводится
+строками после оригинала, поэтому в субтитре бывает до 2*MaxLines строк.
+Высказывания перевода сопоставляются с оригиналом по номеру, если их
+столько же, иначе по наибольшему пересечению во времени.
+*/
+func bilingualCues(res *prerecorderv2.Result, opts Options) []Cue {
+	target, err := Translation(res, opts.Bilingual)
+	if err != nil {
+		// отсутствие перевода проверяется до форматирования, см. checkBilingual
+		return monolingualCues(res, opts)
+	}
+
+	source := res.Transcription.Utterances
+	pairs := alignUtterances(source, target.Utterances)
+
+	var cues []Cue
+	for i, u := range source {
+		words := u.Words
+		if len(words) == 0 {
+			words = spreadWords(u)
+		}
+		split := splitUtterance(u, words, opts)
+
+		var translated []string
+		for _, j := range pairs[i] {
+			translated = append(translated, strings.Fields(target.Utterances[j].Text)...)
+		}
+		for k, part := range distributeWords(translated, split) {
+			split[k].Translation = wrapWords(part, opts.MaxLineLength)
+			split[k].Lines = append(split[k].Lines, split[k].Translation...)
+		}
+		cues = append(cues, split...)
+	}
+	return cues
+}
+
+// Высказывания перевода для каждого высказывания оригинала
+func alignUtterances(source, target []prerecorderv2.Utterance) [][]int {
+	pairs := make([][]int, len(source))
+	if len(source) == len(target) {
+		for i := range source {
+			pairs[i] = []int{i}
+		}
+		return pairs
+	}
+
+	for j, t := range target {
+		best, bestOverlap := -1, 0.0
+		for i, s := range source {
+			if overlap := min(s.End, t.End) - max(s.Start, t.Start); overlap > bestOverlap {
+				best, bestOverlap = i, overlap
+			}
+		}
+		if best >= 0 {
+			pairs[best] = append(pairs[best], j)
+		}
+	}
+	return pairs
+}
+
+// Разделить слова перевода между субтитрами пропорционально количеству слов оригинала
+func distributeWords(words []string, cues []Cue) [][]string {
+	parts := make([][]string, len(cues))
+	total := 0
+	for _, c := range cues {
+		total += len(c.Words)
+	}
+	if total == 0 || len(words) == 0 {
+		return parts
+	}
+
+	taken, seen := 0, 0
+	for k, c := range cues {
+		seen += len(c.Words)
+		upto := len(words) * seen / total
+		if k == len(cues)-1 {
+			upto = len(words)
+		}
+		parts[k] = words[taken:upto]
+		taken = upto
+	}
+	return parts
+}
+
+// Перенос по словам в строки не длиннее width символов
+func wrapWords(words []string, width int) []string {
+	var lines []string
+	line := ""
+	for _, w := range words {
+		switch {
+		case line == "":
+			line = w
+		case len([]rune(line))+1+len([]rune(w)) <= width:
+			line += " " + w
+		default:
+			lines = append(lines, line)
+			line = w
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Есть ли в результате перевод для двуязычных субтитров
+func checkBilingual(resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	if opts.Bilingual == "" || resp == nil || resp.Result == nil {
+		return nil
+	}
+	_, err := Translation(resp.Result, opts.Bilingual)
+	return err
+}
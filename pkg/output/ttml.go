@@ -0,0 +1,122 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+// Пространства имен корня, параметров и стилей
+type ttmlNamespaces struct {
+	root      string
+	parameter string
+	styling   string
+}
+
+var (
+	ttmlNamespace = ttmlNamespaces{
+		root:      "http://www.w3.org/ns/ttml",
+		parameter: "http://www.w3.org/ns/ttml#parameter",
+		styling:   "http://www.w3.org/ns/ttml#styling",
+	}
+	// DFXP - черновик TTML, который до сих пор требуют некоторые платформы
+	dfxpNamespace = ttmlNamespaces{
+		root:      "http://www.w3.org/2006/10/ttaf1",
+		parameter: "http://www.w3.org/2006/10/ttaf1#parameter",
+		styling:   "http://www.w3.org/2006/10/ttaf1#styling",
+	}
+)
+
+func init() {
+	Register("ttml", FormatterFunc(func(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+		return writeTTML(w, resp, opts, ttmlNamespace)
+	}))
+	Register("dfxp", FormatterFunc(func(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+		return writeTTML(w, resp, opts, dfxpNamespace)
+	}))
+}
+
+/*
+# TTML/DFXP субтитры
+
+Без частоты кадров время записывается как медиавремя (00:00:01.200),
+с частотой кадров - как SMPTE таймкод (00:00:01:05) с параметрами
+ttp:frameRate, ttp:frameRateMultiplier и ttp:dropMode.
+*/
+func writeTTML(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options, ns ttmlNamespaces) error {
+	if resp == nil || resp.Result == nil {
+		return errNoResult
+	}
+
+	cues := Cues(resp.Result, opts)
+	if err := validateCues(cues); err != nil {
+		return fmt.Errorf("ttml: %w", err)
+	}
+
+	lang := "en"
+	if langs := resp.Result.Transcription.Languages; len(langs) > 0 {
+		lang = langs[0]
+	}
+
+	timing := `ttp:timeBase="media"`
+	clock := func(sec float64) string { return formatTimecode(sec, ".") }
+	if fr := opts.FrameRate; fr != nil {
+		timing = fmt.Sprintf(`ttp:timeBase="smpte" ttp:frameRate="%d"`, fr.Nominal)
+		if fr.Den == 1001 {
+			timing += ` ttp:frameRateMultiplier="1000 1001"`
+		}
+		if fr.Drop {
+			timing += ` ttp:dropMode="dropNTSC"`
+		} else {
+			timing += ` ttp:dropMode="nonDrop"`
+		}
+		clock = func(sec float64) string {
+			// в TTML кадры всегда отделяются двоеточием
+			return strings.Replace(fr.Timecode(sec), ";", ":", 1)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	fmt.Fprintf(&b, `<tt xmlns="%s" xmlns:ttp="%s" xmlns:tts="%s" %s xml:lang="%s">`+"\n",
+		ns.root, ns.parameter, ns.styling, timing, xmlEscape(lang))
+	b.WriteString("  <head>\n")
+	b.WriteString("    <styling>\n")
+	b.WriteString(`      <style xml:id="default" tts:fontFamily="proportionalSansSerif" tts:fontSize="100%" tts:color="white" tts:backgroundColor="transparent" tts:textAlign="center"/>` + "\n")
+	b.WriteString("    </styling>\n")
+	b.WriteString("    <layout>\n")
+	b.WriteString(`      <region xml:id="bottom" tts:origin="10% 80%" tts:extent="80% 20%" tts:displayAlign="after"/>` + "\n")
+	b.WriteString("    </layout>\n")
+	b.WriteString("  </head>\n")
+	b.WriteString(`  <body style="default" region="bottom">` + "\n")
+	b.WriteString("    <div>\n")
+
+	for idx, cue := range cues {
+		lines := make([]string, 0, len(cue.Lines))
+		for _, line := range cue.Lines {
+			lines = append(lines, xmlEscape(line))
+		}
+		if label := speakerLabel(cue.Speaker, opts); label != "" {
+			lines[0] = xmlEscape(label) + ": " + lines[0]
+		}
+
+		fmt.Fprintf(&b, `      <p xml:id="c%d" begin="%s" end="%s">%s</p>`+"\n",
+			idx+1, clock(cue.Start), clock(cue.End), strings.Join(lines, "<br/>"))
+	}
+
+	b.WriteString("    </div>\n")
+	b.WriteString("  </body>\n")
+	b.WriteString("</tt>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+type ttmlDoc struct {
+	XMLName xml.Name
+	Ps      []struct {
+		Begin string `xml:"begin,attr"`
+		End   string `xml:"end,attr"`
+		Text  string `xml:",chardata"`
+	} `xml:"body>div>p"`
+}
+
+func TestTTMLGolden(t *testing.T) {
+	for _, variant := range []struct {
+		format string
+		ns     ttmlNamespaces
+	}{
+		{"ttml", ttmlNamespace},
+		{"dfxp", dfxpNamespace},
+	} {
+		t.Run(variant.format+"/media", func(t *testing.T) {
+			opts := Options{SpeakerLabels: true}
+			got, err := render(t, variant.format, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, variant.format+"_media", got)
+			checkTTML(t, got, variant.ns, opts)
+		})
+
+		for _, name := range goldenFrameRates {
+			t.Run(variant.format+"/"+name, func(t *testing.T) {
+				opts := Options{SpeakerLabels: true, FrameRate: frameRate(t, name)}
+				got, err := render(t, variant.format, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				checkGolden(t, variant.format+"_"+name, got)
+				checkTTML(t, got, variant.ns, opts)
+			})
+		}
+	}
+}
+
+// Разобрать документ обратно и сверить пространства имен, время и текст субтитров с исходными
+func checkTTML(t *testing.T, data []byte, ns ttmlNamespaces, opts Options) {
+	t.Helper()
+	for _, attr := range []string{
+		fmt.Sprintf(`xmlns:ttp=%q`, ns.parameter),
+		fmt.Sprintf(`xmlns:tts=%q`, ns.styling),
+	} {
+		if !strings.Contains(string(data), attr) {
+			t.Errorf("missing %s", attr)
+		}
+	}
+
+	var doc ttmlDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.XMLName.Space != ns.root || doc.XMLName.Local != "tt" {
+		t.Errorf("root element %s %s, want %s tt", doc.XMLName.Space, doc.XMLName.Local, ns.root)
+	}
+
+	cues := Cues(loadResult(t).Result, opts)
+	if len(doc.Ps) != len(cues) {
+		t.Fatalf("got %d paragraphs, want %d", len(doc.Ps), len(cues))
+	}
+	for i, p := range doc.Ps {
+		cue := cues[i]
+		checkTTMLTime(t, p.Begin, cue.Start, opts.FrameRate)
+		checkTTMLTime(t, p.End, cue.End, opts.FrameRate)
+
+		// <br/> между строками при разборе пропадает
+		want := speakerLabel(cue.Speaker, opts) + ": " + strings.Join(cue.Lines, "")
+		if p.Text != want {
+			t.Errorf("paragraph %d: got %q, want %q", i+1, p.Text, want)
+		}
+	}
+}
+
+func checkTTMLTime(t *testing.T, value string, want float64, fr *FrameRate) {
+	t.Helper()
+	var hh, mm, ss, ff int64
+	if fr == nil {
+		var ms int64
+		if _, err := fmt.Sscanf(value, "%d:%d:%d.%d", &hh, &mm, &ss, &ms); err != nil {
+			t.Fatalf("media time %q: %s", value, err)
+		}
+		got := float64((hh*60+mm)*60+ss) + float64(ms)/1000
+		if math.Abs(got-want) > 0.0005 {
+			t.Errorf("media time %q: got %.3fs, want %.3fs", value, got, want)
+		}
+		return
+	}
+
+	if _, err := fmt.Sscanf(value, "%d:%d:%d:%d", &hh, &mm, &ss, &ff); err != nil {
+		t.Fatalf("smpte time %q: %s", value, err)
+	}
+	if ff >= int64(fr.Nominal) {
+		t.Errorf("smpte time %q: frame %d out of range", value, ff)
+	}
+	checkFrameTime(t, value, *fr, timecodeFrames(*fr, hh, mm, ss, ff), want)
+}
@@ -0,0 +1,70 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"go-gladia.io-client/internal/clients/http/models/prerecorderv2"
+)
+
+func init() {
+	Register("vtt", FormatterFunc(formatVTT))
+}
+
+func formatVTT(w io.Writer, resp *prerecorderv2.PreRecorderResultResponse, opts Options) error {
+	if resp == nil || resp.Result == nil {
+		return errNoResult
+	}
+
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	if opts.LowConfidence > 0 {
+		b.WriteString("STYLE\n::cue(.lowconf) {\n  color: yellow;\n}\n\n")
+	}
+
+	for _, cue := range Cues(resp.Result, opts) {
+		text := vttEscape(strings.Join(cue.Lines, "\n"))
+		if opts.WordTimings || opts.LowConfidence > 0 {
+			text = vttWords(cue, opts)
+		}
+		if label := speakerLabel(cue.Speaker, opts); label != "" {
+			text = "<v " + vttEscape(label) + ">" + text
+		}
+
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", formatTimecode(cue.Start, "."), formatTimecode(cue.End, "."), text)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Текст субтитра по словам: таймкод перед каждым словом, кроме первого, и выделение неуверенных слов
+func vttWords(cue Cue, opts Options) string {
+	lines := make([]string, 0, len(cue.Lines))
+	first := true
+
+	for _, words := range cue.WordLines() {
+		parts := make([]string, 0, len(words))
+		for _, w := range words {
+			text := vttEscape(strings.TrimSpace(w.Word))
+			if opts.LowConfidence > 0 && w.Confidence < opts.LowConfidence {
+				text = "<c.lowconf>" + text + "</c>"
+			}
+			if opts.WordTimings && !first {
+				text = "<" + formatTimecode(w.Start, ".") + ">" + text
+			}
+			first = false
+			parts = append(parts, text)
+		}
+		lines = append(lines, strings.Join(parts, " "))
+	}
+	for _, line := range cue.Translation {
+		lines = append(lines, vttEscape(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func vttEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}